@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/martin-sucha/tzif2text/tzif"
+)
+
+// Severities of analyze findings.
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+	severityInfo    = "info"
+)
+
+// maxAnalyzeUTOff is the largest magnitude of a UT offset that is not reported, 26 hours.
+const maxAnalyzeUTOff = 26 * 60 * 60
+
+// minTransitionGap is the shortest time between transitions that is not reported.
+const minTransitionGap = time.Hour
+
+// analyze prints the suspicious content of f, one finding per line prefixed with name and the severity.
+// Unlike checkData, the findings are not violations of RFC 8536, most of them can occur in valid data.
+// It returns an error if there are findings of severity error.
+func analyze(f *tzif.File, name string) error {
+	b := lastBlock(f)
+	errors := 0
+	report := func(severity, format string, args ...interface{}) {
+		if severity == severityError {
+			errors++
+		}
+		label := severity
+		switch severity {
+		case severityError:
+			label = paint(colorStdout, ansiRed, severity)
+		case severityWarning:
+			label = paint(colorStdout, ansiYellow, severity)
+		}
+		fmt.Fprintf(stdout, "%s: %s: %s\n", name, label, fmt.Sprintf(format, args...))
+	}
+
+	seen := make(map[string]int)
+	for i, t := range b.LocalTimeTypes {
+		if t.UTOff < -maxAnalyzeUTOff || t.UTOff > maxAnalyzeUTOff {
+			report(severityError, "local time type %d: UT offset %s is outside ±26:00", i, formatUTOff(t.UTOff))
+		}
+		desig, err := b.Designation(t.Idx)
+		if err != nil {
+			report(severityError, "local time type %d: %v", i, err)
+		} else if problem := designationProblem(desig); problem != "" {
+			report(severityWarning, "local time type %d: designation %q %s", i, desig, problem)
+		}
+		key := describeType(b, uint8(i))
+		if i < len(b.IsStd) {
+			key += fmt.Sprintf(" std=%t", b.IsStd[i])
+		}
+		if i < len(b.IsUT) {
+			key += fmt.Sprintf(" ut=%t", b.IsUT[i])
+		}
+		if j, ok := seen[key]; ok {
+			report(severityInfo, "local time type %d duplicates local time type %d", i, j)
+		} else {
+			seen[key] = i
+		}
+	}
+
+	for i, ts := range b.TransitionTimes {
+		if i > 0 && ts-b.TransitionTimes[i-1] < int64(minTransitionGap/time.Second) {
+			report(severityWarning, "transition %d at %s is only %s after the previous one",
+				i, diffTime(ts), time.Duration(ts-b.TransitionTimes[i-1])*time.Second)
+		}
+		if i < len(b.TransitionTypes) && int(b.TransitionTypes[i]) < len(b.LocalTimeTypes) && repeatsLocalTime(b, i) {
+			report(severityInfo, "transition %d at %s does not change local time", i, diffTime(ts))
+		}
+	}
+	for _, n := range negativeDSTTypes(b) {
+		report(severityWarning, "local time type %d: negative DST savings %s, first at transition %d",
+			n.typ, formatUTOff(b.LocalTimeTypes[n.typ].UTOff-n.stdUTOff), n.transition)
+	}
+
+	if err := f.CheckV1(); err != nil {
+		report(severityError, "v1 data block inconsistent with v2+ data block: %v", err)
+	}
+	if errors > 0 {
+		return fmt.Errorf("%d error(s) found", errors)
+	}
+	return nil
+}
+
+// designationProblem describes why desig does not follow the recommendation of RFC 8536 to use
+// 3 to 6 ASCII alphanumerics, '-' and '+', or returns an empty string if it does.
+func designationProblem(desig string) string {
+	if len(desig) < 3 {
+		return "is shorter than 3 characters"
+	}
+	if len(desig) > 6 {
+		return "is longer than 6 characters"
+	}
+	for _, c := range desig {
+		if !(c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '+') {
+			return fmt.Sprintf("contains %q", c)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/martin-sucha/tzif2text/tzif"
+)
+
+// assembleMain compiles the zone description in the file at path, or stdin if path is empty,
+// to TZif written to stdout. See assemble for the format.
+func assembleMain(path string) error {
+	r := io.Reader(os.Stdin)
+	name := "<stdin>"
+	if path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r, name = f, path
+	}
+	f, err := assemble(r)
+	if err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	_, err = f.WriteTo(os.Stdout)
+	return err
+}
+
+// assemble reads a zone description, one directive per line, and returns the TZif file it describes.
+// Empty lines and lines starting with # are ignored. The directives are:
+//
+//	version N                  TZif version 1 to 4, 2 if omitted
+//	format slim|fat            v1 data block of version 2+ files, fat if omitted
+//	type UTOFF DST NAME [std] [ut]
+//	                           local time type, indexed in the order of the type lines;
+//	                           std and ut set the standard/wall and UT/local indicators
+//	transition TIME TYPE       transition to the local time type with index TYPE
+//	leap TIME CORR             leap second record
+//	footer [TZ]                TZ string of the footer, which is empty if TZ is omitted
+//	designations "RAW"         raw designations as a Go string literal; NAME of each type line is then
+//	                           its idx instead of a designation
+//
+// TIME is Unix time or RFC 3339. Values are not validated beyond their syntax, so that malformed
+// files can be described too. The v1 data block of fat files is derived from the v2+ data block
+// and footer as by the convert subcommand.
+func assemble(r io.Reader) (*tzif.File, error) {
+	version := uint8(2)
+	fat := true
+	var b tzif.DataBlock
+	var names []string
+	var footer []byte
+	rawDesignations := false
+	indicators := false
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		directive, args, _ := strings.Cut(text, " ")
+		args = strings.TrimSpace(args)
+		fields := strings.Fields(args)
+		var err error
+		switch directive {
+		case "version":
+			var v uint64
+			v, err = strconv.ParseUint(args, 10, 8)
+			if err == nil && (v < 1 || v > 4) {
+				err = fmt.Errorf("unsupported version %d", v)
+			}
+			version = uint8(v)
+		case "format":
+			switch args {
+			case "slim":
+				fat = false
+			case "fat":
+				fat = true
+			default:
+				err = fmt.Errorf("unknown format %q, must be slim or fat", args)
+			}
+		case "type":
+			if len(fields) < 3 {
+				err = fmt.Errorf("type needs UTOFF DST NAME")
+				break
+			}
+			var t tzif.LocalTimeType
+			var utoff int64
+			var dst uint64
+			utoff, err = strconv.ParseInt(fields[0], 10, 32)
+			if err == nil {
+				dst, err = strconv.ParseUint(fields[1], 10, 8)
+			}
+			if err != nil {
+				break
+			}
+			t.UTOff, t.DST = int32(utoff), uint8(dst)
+			var isStd, isUT bool
+			for _, flag := range fields[3:] {
+				switch flag {
+				case "std":
+					isStd = true
+				case "ut":
+					isUT = true
+				default:
+					err = fmt.Errorf("unknown type flag %q", flag)
+				}
+			}
+			indicators = indicators || isStd || isUT
+			b.LocalTimeTypes = append(b.LocalTimeTypes, t)
+			b.IsStd = append(b.IsStd, isStd)
+			b.IsUT = append(b.IsUT, isUT)
+			names = append(names, fields[2])
+		case "transition":
+			if len(fields) != 2 {
+				err = fmt.Errorf("transition needs TIME TYPE")
+				break
+			}
+			var ts int64
+			var typ uint64
+			ts, err = parseAssembleTime(fields[0])
+			if err == nil {
+				typ, err = strconv.ParseUint(fields[1], 10, 8)
+			}
+			b.TransitionTimes = append(b.TransitionTimes, ts)
+			b.TransitionTypes = append(b.TransitionTypes, uint8(typ))
+		case "leap":
+			if len(fields) != 2 {
+				err = fmt.Errorf("leap needs TIME CORR")
+				break
+			}
+			var ls tzif.LeapSecond
+			var corr int64
+			ls.Occur, err = parseAssembleTime(fields[0])
+			if err == nil {
+				corr, err = strconv.ParseInt(fields[1], 10, 32)
+			}
+			ls.Corr = int32(corr)
+			b.LeapSeconds = append(b.LeapSeconds, ls)
+		case "footer":
+			footer = []byte("\n" + args + "\n")
+		case "designations":
+			var s string
+			s, err = strconv.Unquote(args)
+			b.Designations = []byte(s)
+			rawDesignations = true
+		default:
+			err = fmt.Errorf("unknown directive %q", directive)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, name := range names {
+		if rawDesignations {
+			idx, err := strconv.ParseUint(name, 10, 8)
+			if err != nil {
+				return nil, fmt.Errorf("type %d: idx %q: %v", i, name, err)
+			}
+			b.LocalTimeTypes[i].Idx = uint8(idx)
+			continue
+		}
+		idx, err := designationIndex(&b, name)
+		if err != nil {
+			return nil, fmt.Errorf("type %d: %v", i, err)
+		}
+		b.LocalTimeTypes[i].Idx = idx
+	}
+	if !indicators {
+		b.IsStd, b.IsUT = nil, nil
+	}
+	b.Header.Version = version
+
+	if version == 1 {
+		return &tzif.File{V1: b}, nil
+	}
+	if footer == nil {
+		footer = []byte("\n\n")
+	}
+	f := &tzif.File{V2: &b, Footer: footer}
+	if fat {
+		v1, err := tzif.Convert(f, 1)
+		if err != nil {
+			return nil, fmt.Errorf("deriving the v1 data block: %v", err)
+		}
+		f.V1 = v1.V1
+	} else {
+		// The placeholder zic -b slim writes.
+		f.V1 = tzif.DataBlock{
+			LocalTimeTypes: []tzif.LocalTimeType{{}},
+			Designations:   []byte{0},
+		}
+	}
+	f.V1.Header.Version = version
+	return f, nil
+}
+
+// designationIndex returns the index of name in the designations of b, appending it if not present.
+func designationIndex(b *tzif.DataBlock, name string) (uint8, error) {
+	for idx := 0; idx < len(b.Designations); {
+		desig, err := b.Designation(uint8(idx))
+		if err != nil {
+			break
+		}
+		if desig == name {
+			return uint8(idx), nil
+		}
+		idx += len(desig) + 1
+	}
+	if len(b.Designations) > 255 {
+		return 0, fmt.Errorf("no room for designation %q, idx would exceed 255", name)
+	}
+	idx := uint8(len(b.Designations))
+	b.Designations = append(append(b.Designations, name...), 0)
+	return idx, nil
+}
+
+// parseAssembleTime parses a time given as Unix time or RFC 3339.
+func parseAssembleTime(s string) (int64, error) {
+	if t, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q: must be Unix time or RFC 3339", s)
+	}
+	return t.Unix(), nil
+}
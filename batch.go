@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"sync"
+)
+
+// printMu serializes printing, which writes to the package-level stdout and stderr.
+var printMu sync.Mutex
+
+// batchResult is the output of an input processed by a batch worker.
+type batchResult struct {
+	stdout, stderr bytes.Buffer
+	// err is the error processing the input, it is printed by printListed.
+	err error
+}
+
+// processBuffered reads, parses and prints the input called name into the buffers of the result.
+// Reading and parsing run concurrently with other workers, printing takes turns with them.
+func processBuffered(name string) *batchResult {
+	res := &batchResult{}
+	rc, err := openInput(name)
+	if err != nil {
+		res.err = err
+		return res
+	}
+	in := loadInput(rc)
+	rc.Close()
+	printMu.Lock()
+	defer printMu.Unlock()
+	stdout, stderr = &res.stdout, &res.stderr
+	defer func() {
+		stdout, stderr = os.Stdout, os.Stderr
+	}()
+	if err := printInput(in, name); err != nil {
+		res.err = &inputError{name: name, err: err}
+	}
+	return res
+}
+
+// processBatch processes the inputs that are not aliases with n concurrent workers, see processBuffered.
+// The result of inputs[i] is sent to the i-th of the returned channels, so that the results can be
+// flushed in order while the following inputs are still being processed. The channels of aliases are
+// never sent to.
+func processBatch(inputs []zoneEntry, n int) []chan *batchResult {
+	results := make([]chan *batchResult, len(inputs))
+	indexes := make(chan int)
+	for i := range results {
+		results[i] = make(chan *batchResult, 1)
+	}
+	var wg sync.WaitGroup
+	for w := 0; w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] <- processBuffered(inputs[i].name)
+			}
+		}()
+	}
+	go func() {
+		for i, input := range inputs {
+			if input.alias == "" {
+				indexes <- i
+			}
+		}
+		close(indexes)
+		wg.Wait()
+	}()
+	return results
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/martin-sucha/tzif2text/tzif"
+)
+
+// checkViolations returns the RFC 8536 violations found in data.
+func checkViolations(data []byte) []string {
+	var violations []string
+	seen := make(map[string]bool)
+	add := func(prefix string, err error) {
+		if err == nil || seen[err.Error()] {
+			return
+		}
+		seen[err.Error()] = true
+		violations = append(violations, prefix+err.Error())
+	}
+	f, err := tzif.Options{BestEffort: true, Lenient: true}.Parse(bytes.NewReader(data))
+	if err == nil {
+		checkDataBlock("v1 data block: ", &f.V1, add)
+		if f.V2 != nil {
+			checkDataBlock("v2+ data block: ", f.V2, add)
+			_, err := tzif.ParseFooter(f.Footer)
+			add("footer: ", err)
+		}
+		// Recovered sections are incomplete, comparing the data blocks would only report that again.
+		if len(f.V1.Errors) == 0 && (f.V2 == nil || len(f.V2.Errors) == 0) {
+			add("", f.CheckV1())
+			add("", f.CheckHeaders())
+		}
+	}
+	// Strict parsing stops at the first violation, which may already be reported above without the offset.
+	_, err = tzif.Options{Strict: true}.Parse(bytes.NewReader(data))
+	var parseErr *tzif.ParseError
+	if errors.As(err, &parseErr) && seen[parseErr.Err.Error()] {
+		err = nil
+	}
+	add("", err)
+	return violations
+}
+
+// printViolations prints the violations found by checkViolations, one per line prefixed with name,
+// and returns an error if there are any.
+func printViolations(violations []string, name string) error {
+	for _, v := range violations {
+		fmt.Fprintf(stdout, "%s: %s\n", name, v)
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("%d RFC 8536 violation(s)", len(violations))
+	}
+	return nil
+}
+
+func checkDataBlock(prefix string, b *tzif.DataBlock, add func(string, error)) {
+	for _, section := range []tzif.Section{tzif.SectionTransitionTimes, tzif.SectionTransitionTypes,
+		tzif.SectionLocalTimeTypes, tzif.SectionDesignations, tzif.SectionLeapSeconds, tzif.SectionIsStd, tzif.SectionIsUT} {
+		add(prefix+string(section)+": ", b.Errors[section])
+	}
+	for _, w := range b.Warnings {
+		add(prefix, w)
+	}
+	add(prefix, b.CheckTransitionOrder())
+	add(prefix, b.CheckLeapSeconds())
+	for i, t := range b.LocalTimeTypes {
+		if _, err := b.Designation(t.Idx); err != nil {
+			add(prefix, fmt.Errorf("local time type %d: %v", i, err))
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// ANSI SGR codes used by paint.
+const (
+	ansiBold   = "1"
+	ansiRed    = "31"
+	ansiYellow = "33"
+	ansiCyan   = "36"
+)
+
+// colorStdout and colorStderr are set by setupColor if the output written to stdout and stderr
+// respectively is colorized. Colorized standard output is also column-aligned.
+var colorStdout, colorStderr bool
+
+// setupColor enables colors according to -color: always, never, or auto to enable them for outputs
+// that are terminals unless $NO_COLOR is set.
+func setupColor(mode string) error {
+	switch mode {
+	case "always":
+		colorStdout, colorStderr = true, true
+	case "never":
+		colorStdout, colorStderr = false, false
+	case "auto":
+		enabled := os.Getenv("NO_COLOR") == ""
+		colorStdout = enabled && isTerminal(os.Stdout)
+		colorStderr = enabled && isTerminal(os.Stderr)
+	default:
+		return fmt.Errorf("unsupported -color: %q", mode)
+	}
+	return nil
+}
+
+// paint returns s rendered with the SGR code if on is set, otherwise s unchanged.
+func paint(on bool, code, s string) string {
+	if !on || s == "" {
+		return s
+	}
+	return "\x1b[" + code + "m" + s + "\x1b[0m"
+}
+
+// printHeading prints the heading of an output section.
+func printHeading(s string) {
+	fmt.Fprintln(stdout, paint(colorStdout, ansiBold, s))
+}
+
+// warnf prints a warning to stderr.
+func warnf(format string, args ...interface{}) {
+	fmt.Fprintf(stderr, "%s %s\n", paint(colorStderr, ansiYellow, "warning:"), fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/martin-sucha/tzif2text/tzif"
+)
+
+// convertMain writes the file at path, or stdin if path is empty, converted to version to stdout.
+func convertMain(to, path string) error {
+	version, err := strconv.ParseUint(to, 10, 8)
+	if err != nil {
+		return fmt.Errorf("invalid version %q", to)
+	}
+	var f *tzif.File
+	if path == "" {
+		f, err = parseInput(os.Stdin)
+	} else {
+		f, err = parsePath(path)
+	}
+	if err != nil {
+		return err
+	}
+	out, err := tzif.Convert(f, uint8(version))
+	if err != nil {
+		return err
+	}
+	_, err = out.WriteTo(os.Stdout)
+	return err
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/martin-sucha/tzif2text/tzif"
+)
+
+// printCSV prints the transitions of the most precise data block as a CSV table with a header row, or its
+// local time type records if -section selects the types, so that the output loads directly into tools
+// expecting a single table. comma is the field separator.
+func printCSV(f *tzif.File, comma rune) error {
+	b := lastBlock(f)
+	w := csv.NewWriter(stdout)
+	w.Comma = comma
+	if sections["types"] {
+		w.Write([]string{"index", "utoff", "dst", "idx", "designation"})
+		for i, t := range b.LocalTimeTypes {
+			desig, _ := designation(b, t.Idx)
+			w.Write([]string{strconv.Itoa(i), strconv.Itoa(int(t.UTOff)), strconv.Itoa(int(t.DST)), strconv.Itoa(int(t.Idx)), desig})
+		}
+	} else {
+		w.Write([]string{"unix_ts", "utc_iso", "local_iso", "utoff_seconds", "dst", "abbreviation", "type_index"})
+		for i, ts := range b.TransitionTimes {
+			if !inRange(ts) {
+				continue
+			}
+			utc := time.Unix(ts, 0).UTC()
+			row := []string{strconv.FormatInt(ts, 10), utc.Format(time.RFC3339), "", "", "", "", ""}
+			if i < len(b.TransitionTypes) {
+				tt := b.TransitionTypes[i]
+				row[6] = strconv.Itoa(int(tt))
+				if int(tt) < len(b.LocalTimeTypes) {
+					t := b.LocalTimeTypes[tt]
+					row[2] = utc.In(time.FixedZone("", int(t.UTOff))).Format(time.RFC3339)
+					row[3] = strconv.Itoa(int(t.UTOff))
+					row[4] = strconv.Itoa(int(t.DST))
+					row[5], _ = designation(b, t.Idx)
+				}
+			}
+			w.Write(row)
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// checkCSVSections reports an error unless -section selects at most one of the tables printCSV prints.
+func checkCSVSections() error {
+	switch {
+	case sections["transitions"] && sections["types"]:
+		return fmt.Errorf("CSV output holds one table, select either -section transitions or -section types")
+	case len(sections) > 0 && !sections["transitions"] && !sections["types"]:
+		return fmt.Errorf("CSV output has no %s table, only transitions and types", sections)
+	}
+	return nil
+}
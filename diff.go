@@ -0,0 +1,371 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/martin-sucha/tzif2text/tzif"
+)
+
+// errDiffer is returned by diffMain if the files differ. It is not printed, only the exit status is set.
+var errDiffer = fmt.Errorf("files differ")
+
+// diffMain compares the file at oldPath with the file at newPath, or stdin if newPath is empty.
+func diffMain(oldPath, newPath string) error {
+	old, err := parsePath(oldPath)
+	if err != nil {
+		return err
+	}
+	var cur *tzif.File
+	if newPath == "" {
+		cur, err = parseInput(os.Stdin)
+	} else {
+		cur, err = parsePath(newPath)
+	}
+	if err != nil {
+		return err
+	}
+	differ, err := diffFiles(old, cur)
+	if err != nil {
+		return err
+	}
+	if differ {
+		return errDiffer
+	}
+	return nil
+}
+
+// parsePath parses the TZif file at path, prefixing errors with the path.
+func parsePath(path string) (*tzif.File, error) {
+	f, err := openInput(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	tf, err := parseInput(f)
+	if err != nil {
+		return nil, &inputError{name: path, err: err}
+	}
+	return tf, nil
+}
+
+// parseInput parses TZif data read from r, decompressing it if needed.
+func parseInput(r io.Reader) (*tzif.File, error) {
+	r, err := gunzip(r)
+	if err != nil {
+		return nil, err
+	}
+	return parseOptions().Parse(r)
+}
+
+// lastBlock returns the most precise data block of the file, v2+ if present.
+func lastBlock(f *tzif.File) *tzif.DataBlock {
+	if f.V2 != nil {
+		return f.V2
+	}
+	return &f.V1
+}
+
+// fileDiff is the difference between two files found by compareFiles, in the form printed by -diff-json.
+// All properties are always present: unchanged initial local time and footer are null and the lists of
+// changes are empty.
+type fileDiff struct {
+	Differ      bool              `json:"differ"`
+	Initial     *zoneChange       `json:"initial"`
+	Transitions transitionChanges `json:"transitions"`
+	Types       typeChanges       `json:"types"`
+	LeapSeconds leapChanges       `json:"leap_seconds"`
+	Footer      *footerChange     `json:"footer"`
+}
+
+// diffZone is a local time of the Canonical form of a file.
+type diffZone struct {
+	Designation string `json:"designation"`
+	UTOff       int32  `json:"utoff"`
+	DST         bool   `json:"dst"`
+}
+
+// zoneChange is a change of a local time, Before is nil if it was added and After if it was removed.
+type zoneChange struct {
+	Before *diffZone `json:"before"`
+	After  *diffZone `json:"after"`
+}
+
+type transitionChange struct {
+	Time   jsonTime  `json:"time"`
+	Before *diffZone `json:"before"`
+	After  *diffZone `json:"after"`
+}
+
+type transitionChanges struct {
+	Added   []transitionChange `json:"added"`
+	Removed []transitionChange `json:"removed"`
+	Changed []transitionChange `json:"changed"`
+}
+
+// typeChanges lists the local times used by only one of the files, see zoneSet.
+type typeChanges struct {
+	Added   []zoneChange `json:"added"`
+	Removed []zoneChange `json:"removed"`
+}
+
+type diffLeap struct {
+	Occur jsonTime `json:"occur"`
+	Corr  int32    `json:"corr"`
+}
+
+// leapChange is a change of the leap second record at Index.
+type leapChange struct {
+	Index  int       `json:"index"`
+	Before *diffLeap `json:"before"`
+	After  *diffLeap `json:"after"`
+}
+
+type leapChanges struct {
+	Added   []leapChange `json:"added"`
+	Removed []leapChange `json:"removed"`
+	Changed []leapChange `json:"changed"`
+}
+
+// footerChange is a change of the footer, with the raw footers as printed by -json. Before or After is nil
+// for a file without one.
+type footerChange struct {
+	Before *string `json:"before"`
+	After  *string `json:"after"`
+}
+
+// diffFiles prints the differences between old and cur and reports whether there were any. With -diff-json,
+// the fileDiff is printed as a JSON object. Otherwise it is printed line by line, lines starting with - are
+// only in old, + only in cur and ~ changed between the two.
+func diffFiles(old, cur *tzif.File) (bool, error) {
+	d, err := compareFiles(old, cur)
+	if err != nil {
+		return false, err
+	}
+	if *diffJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return d.Differ, enc.Encode(d)
+	}
+	printDiff(d)
+	return d.Differ, nil
+}
+
+// compareFiles returns the differences between old and cur.
+// The Canonical forms of the files are compared, so the files differ exactly if tzif.File.Equal reports
+// them unequal; differences in the encoding that do not change the meaning, such as the order of local
+// time type records or designations, the version or the transitions a fat file lists and the footer
+// TZ string generates, are not reported.
+func compareFiles(old, cur *tzif.File) (*fileDiff, error) {
+	ac, err := old.Canonical()
+	if err != nil {
+		return nil, err
+	}
+	bc, err := cur.Canonical()
+	if err != nil {
+		return nil, err
+	}
+	d := &fileDiff{
+		Transitions: transitionChanges{Added: []transitionChange{}, Removed: []transitionChange{}, Changed: []transitionChange{}},
+		Types:       typeChanges{Added: []zoneChange{}, Removed: []zoneChange{}},
+		LeapSeconds: leapChanges{Added: []leapChange{}, Removed: []leapChange{}, Changed: []leapChange{}},
+	}
+
+	if ac.Initial != bc.Initial {
+		d.Initial = &zoneChange{Before: newDiffZone(ac.Initial), After: newDiffZone(bc.Initial)}
+	}
+	aTrans, bTrans := transitionMap(ac), transitionMap(bc)
+	var times []int64
+	for ts := range aTrans {
+		times = append(times, ts)
+	}
+	for ts := range bTrans {
+		if _, ok := aTrans[ts]; !ok {
+			times = append(times, ts)
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	for _, ts := range times {
+		at, aok := aTrans[ts]
+		bt, bok := bTrans[ts]
+		switch {
+		case !bok:
+			d.Transitions.Removed = append(d.Transitions.Removed, transitionChange{Time: newJSONTime(ts), Before: newDiffZone(at)})
+		case !aok:
+			d.Transitions.Added = append(d.Transitions.Added, transitionChange{Time: newJSONTime(ts), After: newDiffZone(bt)})
+		case at != bt:
+			d.Transitions.Changed = append(d.Transitions.Changed,
+				transitionChange{Time: newJSONTime(ts), Before: newDiffZone(at), After: newDiffZone(bt)})
+		}
+	}
+
+	// Local time types are compared as the set of local times the transitions switch to, so the order,
+	// duplicates and unused ones of the records are not reported.
+	aTypes, bTypes := zoneSet(ac), zoneSet(bc)
+	for _, z := range sortedZones(aTypes) {
+		if !bTypes[z] {
+			d.Types.Removed = append(d.Types.Removed, zoneChange{Before: newDiffZone(z)})
+		}
+	}
+	for _, z := range sortedZones(bTypes) {
+		if !aTypes[z] {
+			d.Types.Added = append(d.Types.Added, zoneChange{After: newDiffZone(z)})
+		}
+	}
+
+	for i := 0; i < len(ac.LeapSeconds) || i < len(bc.LeapSeconds); i++ {
+		switch {
+		case i >= len(bc.LeapSeconds):
+			d.LeapSeconds.Removed = append(d.LeapSeconds.Removed, leapChange{Index: i, Before: newDiffLeap(ac.LeapSeconds[i])})
+		case i >= len(ac.LeapSeconds):
+			d.LeapSeconds.Added = append(d.LeapSeconds.Added, leapChange{Index: i, After: newDiffLeap(bc.LeapSeconds[i])})
+		case ac.LeapSeconds[i] != bc.LeapSeconds[i]:
+			d.LeapSeconds.Changed = append(d.LeapSeconds.Changed,
+				leapChange{Index: i, Before: newDiffLeap(ac.LeapSeconds[i]), After: newDiffLeap(bc.LeapSeconds[i])})
+		}
+	}
+
+	if ac.HasFooter != bc.HasFooter || (ac.Footer == nil) != (bc.Footer == nil) ||
+		(ac.Footer != nil && ac.Footer.String() != bc.Footer.String()) {
+		d.Footer = &footerChange{Before: footerString(old), After: footerString(cur)}
+	}
+	d.Differ = d.Initial != nil || d.Footer != nil ||
+		len(d.Transitions.Added)+len(d.Transitions.Removed)+len(d.Transitions.Changed) > 0 ||
+		len(d.Types.Added)+len(d.Types.Removed) > 0 ||
+		len(d.LeapSeconds.Added)+len(d.LeapSeconds.Removed)+len(d.LeapSeconds.Changed) > 0
+	return d, nil
+}
+
+// printDiff prints d line by line, the transitions and leap seconds in the order of their time and index.
+func printDiff(d *fileDiff) {
+	if d.Initial != nil {
+		fmt.Fprintf(stdout, "~ initial %s -> %s\n", d.Initial.Before, d.Initial.After)
+	}
+	transitions := append(append(append([]transitionChange(nil), d.Transitions.Added...), d.Transitions.Removed...),
+		d.Transitions.Changed...)
+	sort.Slice(transitions, func(i, j int) bool { return transitions[i].Time.Unix < transitions[j].Time.Unix })
+	for _, t := range transitions {
+		switch {
+		case t.After == nil:
+			fmt.Fprintf(stdout, "- transition %s %s\n", diffTime(t.Time.Unix), t.Before)
+		case t.Before == nil:
+			fmt.Fprintf(stdout, "+ transition %s %s\n", diffTime(t.Time.Unix), t.After)
+		default:
+			fmt.Fprintf(stdout, "~ transition %s %s -> %s\n", diffTime(t.Time.Unix), t.Before, t.After)
+		}
+	}
+	for _, z := range d.Types.Removed {
+		fmt.Fprintf(stdout, "- type %s\n", z.Before)
+	}
+	for _, z := range d.Types.Added {
+		fmt.Fprintf(stdout, "+ type %s\n", z.After)
+	}
+	leaps := append(append(append([]leapChange(nil), d.LeapSeconds.Added...), d.LeapSeconds.Removed...),
+		d.LeapSeconds.Changed...)
+	sort.Slice(leaps, func(i, j int) bool { return leaps[i].Index < leaps[j].Index })
+	for _, l := range leaps {
+		switch {
+		case l.After == nil:
+			fmt.Fprintf(stdout, "- leap (%d) %s\n", l.Index, l.Before)
+		case l.Before == nil:
+			fmt.Fprintf(stdout, "+ leap (%d) %s\n", l.Index, l.After)
+		default:
+			fmt.Fprintf(stdout, "~ leap (%d) %s -> %s\n", l.Index, l.Before, l.After)
+		}
+	}
+	if d.Footer != nil {
+		var before, after string
+		if d.Footer.Before != nil {
+			before = *d.Footer.Before
+		}
+		if d.Footer.After != nil {
+			after = *d.Footer.After
+		}
+		fmt.Fprintf(stdout, "~ footer %q -> %q\n", before, after)
+	}
+}
+
+func newDiffZone(z tzif.Zone) *diffZone {
+	return &diffZone{Designation: z.Name, UTOff: z.UTOff, DST: z.DST}
+}
+
+// String describes z like describeType.
+func (z *diffZone) String() string {
+	return describeZone(tzif.Zone{Name: z.Designation, UTOff: z.UTOff, DST: z.DST})
+}
+
+func newDiffLeap(ls tzif.LeapSecond) *diffLeap {
+	return &diffLeap{Occur: newJSONTime(ls.Occur), Corr: ls.Corr}
+}
+
+func (l *diffLeap) String() string {
+	return fmt.Sprintf("%s corr=%d", diffTime(l.Occur.Unix), l.Corr)
+}
+
+// footerString returns the footer of f, nil if it has none.
+func footerString(f *tzif.File) *string {
+	if f.V2 == nil {
+		return nil
+	}
+	footer := string(f.Footer)
+	return &footer
+}
+
+// zoneSet returns the local times c uses, before the first transition and after each.
+func zoneSet(c *tzif.Canonical) map[tzif.Zone]bool {
+	m := map[tzif.Zone]bool{c.Initial: true}
+	for _, t := range c.Transitions {
+		m[t.Zone] = true
+	}
+	return m
+}
+
+// sortedZones returns the keys of m in the order of their descriptions.
+func sortedZones(m map[tzif.Zone]bool) []tzif.Zone {
+	zones := make([]tzif.Zone, 0, len(m))
+	for z := range m {
+		zones = append(zones, z)
+	}
+	sort.Slice(zones, func(i, j int) bool { return describeZone(zones[i]) < describeZone(zones[j]) })
+	return zones
+}
+
+// transitionMap maps each transition time of c to the local time it switches to.
+func transitionMap(c *tzif.Canonical) map[int64]tzif.Zone {
+	m := make(map[int64]tzif.Zone, len(c.Transitions))
+	for _, t := range c.Transitions {
+		m[t.Unix] = t.Zone
+	}
+	return m
+}
+
+// describeZone describes z like describeType.
+func describeZone(z tzif.Zone) string {
+	dst := 0
+	if z.DST {
+		dst = 1
+	}
+	return fmt.Sprintf("%s utoff=%d dst=%d", z.Name, z.UTOff, dst)
+}
+
+// describeType describes local time type i by its offset, DST flag and designation,
+// so that types compare equal regardless of their index or position of the designation.
+func describeType(b *tzif.DataBlock, i uint8) string {
+	if int(i) >= len(b.LocalTimeTypes) {
+		return fmt.Sprintf("(type %d out of range)", i)
+	}
+	t := b.LocalTimeTypes[i]
+	desig, err := b.Designation(t.Idx)
+	if err != nil {
+		desig = "?"
+	}
+	return fmt.Sprintf("%s utoff=%d dst=%d", desig, t.UTOff, t.DST)
+}
+
+func diffTime(ts int64) string {
+	return fmt.Sprintf("%d (%s)", ts, time.Unix(ts, 0).UTC().Format(time.RFC3339))
+}
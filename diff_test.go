@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCompareFiles(t *testing.T) {
+	d, err := compareFiles(readTestFile(t, "prague-fat.tzif"), readTestFile(t, "prague-slim.tzif"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The lists are empty rather than null, so that the document has the same shape either way.
+	want := `{"differ":false,"initial":null,"transitions":{"added":[],"removed":[],"changed":[]},` +
+		`"types":{"added":[],"removed":[]},"leap_seconds":{"added":[],"removed":[],"changed":[]},"footer":null}`
+	if string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+
+	utc, right := readTestFile(t, "utc.tzif"), readTestFile(t, "right-utc.tzif")
+	d, err = compareFiles(utc, right)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Differ || len(d.LeapSeconds.Added) != len(lastBlock(right).LeapSeconds) || len(d.LeapSeconds.Removed) != 0 {
+		t.Errorf("utc -> right/UTC: %+v, want %d added leap seconds", d, len(lastBlock(right).LeapSeconds))
+	}
+	for i, l := range d.LeapSeconds.Added {
+		if l.Index != i || l.Before != nil || l.After.Corr != lastBlock(right).LeapSeconds[i].Corr {
+			t.Errorf("added leap second %d: %+v", i, l)
+		}
+	}
+
+	d, err = compareFiles(readTestFile(t, "prague-slim.tzif"), readTestFile(t, "tokyo-slim.tzif"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Initial == nil || d.Footer == nil || !strings.Contains(*d.Footer.Before, "CET") || !strings.Contains(*d.Footer.After, "JST") {
+		t.Errorf("prague -> tokyo: initial %+v, footer %+v", d.Initial, d.Footer)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/martin-sucha/tzif2text/tzif"
+)
+
+// referenceZones are zones of tzdata 2025b, compiled by zic and written by the normalize subcommand,
+// for -against-embedded to compare inputs with.
+//
+//go:embed reference
+var referenceZones embed.FS
+
+// embeddedZone returns the name of the embedded reference zone the input called name is a file of:
+// the longest suffix of its slash-separated path that is an embedded zone, such as Europe/Prague for
+// /usr/share/zoneinfo/Europe/Prague. It returns false if there is none.
+func embeddedZone(name string) (string, bool) {
+	parts := strings.Split(strings.ReplaceAll(name, "\\", "/"), "/")
+	for i := range parts {
+		zone := path.Join(parts[i:]...)
+		if fi, err := fs.Stat(referenceZones, path.Join("reference", zone)); err == nil && fi.Mode().IsRegular() {
+			return zone, true
+		}
+	}
+	return "", false
+}
+
+// embeddedZoneNames returns the names of the embedded reference zones in increasing order.
+func embeddedZoneNames() []string {
+	var names []string
+	fs.WalkDir(referenceZones, "reference", func(p string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			names = append(names, strings.TrimPrefix(p, "reference/"))
+		}
+		return err
+	})
+	sort.Strings(names)
+	return names
+}
+
+// diffEmbedded prints the differences between the embedded reference zone matching the input called name
+// and f, read from it, as diffFiles does. It returns an error if they differ or there is no such zone.
+func diffEmbedded(f *tzif.File, name string) error {
+	zone, ok := embeddedZone(name)
+	if !ok {
+		return fmt.Errorf("the path matches no embedded reference zone, they are %s",
+			strings.Join(embeddedZoneNames(), ", "))
+	}
+	data, err := referenceZones.ReadFile(path.Join("reference", zone))
+	if err != nil {
+		return err
+	}
+	ref, err := tzif.ParseBytes(data)
+	if err != nil {
+		return fmt.Errorf("embedded %s: %v", zone, err)
+	}
+	differ, err := diffFiles(ref, f)
+	if err != nil {
+		return err
+	}
+	if differ {
+		return fmt.Errorf("differs from the embedded %s of tzdata 2025b", zone)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/martin-sucha/tzif2text/tzif"
+)
+
+func TestEmbeddedZone(t *testing.T) {
+	for name, want := range map[string]string{
+		"/usr/share/zoneinfo/Europe/Prague": "Europe/Prague",
+		"Europe/Prague":                     "Europe/Prague",
+		`C:\zoneinfo\Asia\Tokyo`:            "Asia/Tokyo",
+		"/usr/share/zoneinfo/Etc/UTC":       "Etc/UTC",
+		"/usr/share/zoneinfo/Europe/Berlin": "",
+		"Prague":                            "",
+	} {
+		if got, ok := embeddedZone(name); got != want || ok != (want != "") {
+			t.Errorf("embeddedZone(%q) = %q, %t, want %q", name, got, ok, want)
+		}
+	}
+	names := embeddedZoneNames()
+	if len(names) == 0 {
+		t.Fatal("no embedded zones")
+	}
+	for _, name := range names {
+		data, err := referenceZones.ReadFile("reference/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := tzif.ParseBytes(data)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if err := diffEmbedded(f, name); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
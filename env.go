@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/martin-sucha/tzif2text/tzif"
+)
+
+// printTZEnv explains the POSIX TZ string in the TZ environment variable like the footer of a file.
+// If TZ names a zone instead, an error suggesting -zone is returned.
+func printTZEnv() error {
+	value, ok := os.LookupEnv("TZ")
+	if !ok {
+		return fmt.Errorf("TZ is not set, local time is read from /etc/localtime")
+	}
+	if value == "" {
+		return fmt.Errorf("TZ is empty, which most systems treat as UTC")
+	}
+	// A leading colon selects an implementation-defined format, which is a zone name on common systems.
+	name, colon := strings.CutPrefix(value, ":")
+	if !colon {
+		tz, err := tzif.ParseTZString(value)
+		if err == nil {
+			printHeading("TZ:")
+			fmt.Fprintf(stdout, "%q\n", value)
+			printTZString(&tz)
+			return nil
+		}
+		if !isZoneName(value) {
+			return fmt.Errorf("TZ: %v", err)
+		}
+	}
+	return fmt.Errorf("TZ %q is a zone name, not a POSIX TZ string; print the zone with -zone %s", value, name)
+}
+
+// isZoneName reports whether name looks like a zone name rather than a malformed TZ string: it has
+// a slash like Europe/Prague, or there is a file of that name in the zoneinfo directory.
+func isZoneName(name string) bool {
+	if strings.Contains(name, "/") {
+		return true
+	}
+	fi, err := os.Stat(filepath.Join(zoneinfoDir(""), name))
+	return err == nil && fi.Mode().IsRegular()
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/martin-sucha/tzif2text/tzif"
+)
+
+// Exit statuses by the kind of failure. Status 2 is left to the flag package, which uses it for invalid flags.
+const (
+	// exitFailure is the status of validation failures, such as -check violations or differing -diff inputs,
+	// and of errors of other kinds.
+	exitFailure = 1
+	// exitParse is the status of malformed input.
+	exitParse = 3
+	// exitIO is the status of input that could not be read.
+	exitIO = 4
+)
+
+// inputError is an error processing the input called name.
+type inputError struct {
+	name string
+	err  error
+}
+
+func (e *inputError) Error() string {
+	return e.name + ": " + e.err.Error()
+}
+
+func (e *inputError) Unwrap() error {
+	return e.err
+}
+
+// inputsFailedError is returned if some of several inputs failed, status is the highest exit status of theirs.
+type inputsFailedError struct {
+	failed, total, status int
+}
+
+func (e *inputsFailedError) Error() string {
+	return fmt.Sprintf("%d of %d files failed", e.failed, e.total)
+}
+
+// exitStatus returns the exit status of the failure err.
+func exitStatus(err error) int {
+	var failed *inputsFailedError
+	var parseErr *tzif.ParseError
+	var pathErr *fs.PathError
+	switch {
+	case errors.As(err, &failed):
+		return failed.status
+	case errors.As(err, &parseErr):
+		return exitParse
+	case errors.As(err, &pathErr):
+		return exitIO
+	}
+	return exitFailure
+}
+
+// jsonError is the object printed by -error-format=json for each error.
+type jsonError struct {
+	Error      string `json:"error"`
+	Kind       string `json:"kind"`
+	ExitStatus int    `json:"exit_status"`
+	Input      string `json:"input,omitempty"`
+	Offset     *int64 `json:"offset,omitempty"`
+	Field      string `json:"field,omitempty"`
+	Expected   string `json:"expected,omitempty"`
+	Actual     string `json:"actual,omitempty"`
+}
+
+// exitKinds name the kinds of failures in jsonError.
+var exitKinds = map[int]string{
+	exitFailure: "failure",
+	exitParse:   "parse",
+	exitIO:      "io",
+}
+
+// printError prints err to stderr in the format set by -error-format.
+func printError(err error) {
+	if *errorFormat != "json" {
+		fmt.Fprintln(stderr, paint(colorStderr, ansiRed, err.Error()))
+		return
+	}
+	status := exitStatus(err)
+	e := jsonError{Error: err.Error(), Kind: exitKinds[status], ExitStatus: status}
+	var input *inputError
+	var pathErr *fs.PathError
+	if errors.As(err, &input) {
+		e.Input = input.name
+	} else if errors.As(err, &pathErr) {
+		e.Input = pathErr.Path
+	}
+	var parseErr *tzif.ParseError
+	if errors.As(err, &parseErr) {
+		e.Offset = &parseErr.Offset
+		e.Field, e.Expected, e.Actual = parseErr.Field, parseErr.Expected, parseErr.Actual
+	}
+	data, jsonErr := json.Marshal(e)
+	if jsonErr != nil {
+		fmt.Fprintln(stderr, err)
+		return
+	}
+	fmt.Fprintln(stderr, string(data))
+}
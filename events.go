@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// knownEvent is a well-known change of the DST rules that took effect with a transition, annotated in
+// the -resolve output with -annotate-known-events.
+type knownEvent struct {
+	// zones are the zones the event applies to.
+	zones []string
+	// date is the date of the transition in the local time in effect before it, as "2006-01-02".
+	date string
+	// description describes the event and names its source.
+	description string
+}
+
+// euZones, usZones and auZones are the zones of the events that applied to several of them. The table is
+// deliberately small: it covers the main zones affected, not all of them.
+var (
+	euZones = []string{"Europe/Berlin", "Europe/Madrid", "Europe/Paris", "Europe/Prague", "Europe/Rome", "Europe/Vienna"}
+	usZones = []string{"America/Chicago", "America/Denver", "America/Los_Angeles", "America/New_York"}
+	auZones = []string{"Australia/Melbourne", "Australia/Sydney"}
+)
+
+// knownEvents are the events known to -annotate-known-events.
+var knownEvents = []knownEvent{
+	{euZones, "1996-10-27", "EU harmonization: summer time ends on the last Sunday of October (Directive 94/21/EC)"},
+	{usZones, "2007-03-11", "US Energy Policy Act of 2005: DST starts on the second Sunday of March (Pub. L. 109-58, sec. 110)"},
+	{usZones, "2007-11-04", "US Energy Policy Act of 2005: DST ends on the first Sunday of November (Pub. L. 109-58, sec. 110)"},
+	{[]string{"Europe/Moscow"}, "2011-03-27", "Russia keeps summer time all year, UTC+04:00 (Federal Law 107-FZ of 3 June 2011)"},
+	{[]string{"Europe/Moscow"}, "2014-10-26", "Russia returns to UTC+03:00 permanently (Federal Law 248-FZ of 21 July 2014)"},
+	{auZones, "2008-04-06", "south-eastern Australia harmonizes DST: it ends on the first Sunday of April (tzdata australasia, Rule AN 2008)"},
+	{auZones, "2008-10-05", "south-eastern Australia harmonizes DST: it starts on the first Sunday of October (tzdata australasia, Rule AN 2008)"},
+}
+
+// knownEventAt returns the description of the known event of the zone the input called name is a file of,
+// at the transition at Unix time ts from a local time with offset utoff, or an empty string if there is none.
+// The input matches a zone if its slash-separated path ends with the zone name.
+func knownEventAt(name string, ts int64, utoff int32) string {
+	name = strings.ReplaceAll(name, "\\", "/")
+	date := time.Unix(ts+int64(utoff), 0).UTC().Format("2006-01-02")
+	for _, e := range knownEvents {
+		if e.date != date {
+			continue
+		}
+		for _, zone := range e.zones {
+			if name == zone || strings.HasSuffix(name, "/"+zone) {
+				return e.description
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/martin-sucha/tzif2text/tzif"
+)
+
+// verifyFooter compares the last n transitions of the v2+ data block with the footer TZ string projected
+// over the years they cover. The local times are compared at each transition of either and the second
+// before it, within the span of the n transitions. Mismatches are printed one per line prefixed with name,
+// followed by the first year with a mismatch, and an error is returned if there are any.
+func verifyFooter(f *tzif.File, n int, name string) error {
+	if f.V2 == nil {
+		return fmt.Errorf("version 1 files have no footer")
+	}
+	tz, err := parseFooter(f)
+	if err != nil {
+		return err
+	}
+	if tz == nil {
+		return fmt.Errorf("footer has no TZ string")
+	}
+	b := f.V2
+	times := b.TransitionTimes[max(0, len(b.TransitionTimes)-n):]
+	if len(times) == 0 {
+		return nil
+	}
+	lo, hi := times[0], times[len(times)-1]
+	points := append([]int64(nil), times...)
+	for year := time.Unix(lo, 0).UTC().Year(); year <= time.Unix(hi, 0).UTC().Year(); year++ {
+		start, end, ok := tz.Transitions(year)
+		if !ok {
+			break
+		}
+		for _, t := range []int64{start, end} {
+			if t >= lo && t <= hi {
+				points = append(points, t)
+			}
+		}
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i] < points[j] })
+	mismatches := 0
+	var first int64
+	for i, p := range points {
+		if i > 0 && p == points[i-1] {
+			continue
+		}
+		for _, t := range []int64{p - 1, p} {
+			if t < lo {
+				continue
+			}
+			z, err := b.Lookup(t)
+			if err != nil {
+				return err
+			}
+			fz := tz.Lookup(t)
+			if z.Name != fz.Name || z.UTOff != fz.UTOff || z.DST != fz.DST {
+				fmt.Fprintf(stdout, "%s: at %s: transition table %s, footer %s\n",
+					name, diffTime(t), describeZone(z), describeZone(fz))
+				if mismatches == 0 {
+					first = t
+				}
+				mismatches++
+			}
+		}
+	}
+	if mismatches > 0 {
+		fmt.Fprintf(stdout, "%s: first mismatching year: %d\n", name, time.Unix(first, 0).UTC().Year())
+		return fmt.Errorf("footer TZ string %s disagrees with the last %d transitions at %d instant(s)", tz, len(times), mismatches)
+	}
+	return nil
+}
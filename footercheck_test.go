@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestVerifyFooter(t *testing.T) {
+	var buf bytes.Buffer
+	stdout = &buf
+	defer func() { stdout = os.Stdout }()
+
+	f := readTestFile(t, "prague-fat.tzif")
+	if err := verifyFooter(f, 20, "prague"); err != nil || buf.Len() > 0 {
+		t.Errorf("prague-fat: %v\n%s", err, buf.Bytes())
+	}
+
+	// DST ending a week early, which contradicts the transitions zic generated from the rules.
+	f.Footer = []byte("\nCET-1CEST,M3.5.0,M10.4.0/3\n")
+	buf.Reset()
+	if err := verifyFooter(f, 20, "prague"); err == nil {
+		t.Errorf("prague-fat with a bad footer: no error")
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) < 2 || !strings.HasPrefix(lines[len(lines)-1], "prague: first mismatching year: ") {
+		t.Errorf("prague-fat with a bad footer:\n%s", buf.Bytes())
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	gofmt "go/format"
+	"go/token"
+	"os"
+	"time"
+
+	"github.com/martin-sucha/tzif2text/tzif"
+)
+
+// genMain writes Go source declaring the zone in the file at path, or stdin if path is empty,
+// as the variable -var of package -package to stdout.
+func genMain(path string) error {
+	if !token.IsIdentifier(*genPackage) {
+		return fmt.Errorf("invalid -package: %q", *genPackage)
+	}
+	if !token.IsIdentifier(*genVar) {
+		return fmt.Errorf("invalid -var: %q", *genVar)
+	}
+	var f *tzif.File
+	var err error
+	source := path
+	if path == "" {
+		f, err = parseInput(os.Stdin)
+		source = "standard input"
+	} else {
+		f, err = parsePath(path)
+	}
+	if err != nil {
+		return err
+	}
+	src, err := genSource(f, source, *genPackage, *genVar)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(src)
+	return err
+}
+
+// genSource returns gofmt-formatted Go source declaring the local time types, transitions and footer rule of f
+// as variable name in package pkg. The output depends only on the arguments, so it is reproducible.
+func genSource(f *tzif.File, source, pkg, name string) ([]byte, error) {
+	b := lastBlock(f)
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by tzif2text gen from %s. DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+	fmt.Fprintf(&buf, "// %s is the time zone read from %s.\n", name, source)
+	fmt.Fprintf(&buf, "var %s = struct {\n", name)
+	buf.WriteString("\t// Types are the local time types, indexed by Transitions[i].Type.\n")
+	buf.WriteString("\tTypes []struct {\n\t\tName string\n\t\tUTOff int32\n\t\tDST bool\n\t}\n")
+	buf.WriteString("\t// Transitions are the Unix times at which local time switches to another type, in ascending order.\n")
+	buf.WriteString("\t// Types[0] is in effect before the first transition.\n")
+	buf.WriteString("\tTransitions []struct {\n\t\tAt int64\n\t\tType uint8\n\t}\n")
+	buf.WriteString("\t// Rule is the TZ string in effect after the last transition, empty if there is none.\n")
+	buf.WriteString("\tRule string\n")
+	buf.WriteString("}{\n")
+	buf.WriteString("\tTypes: []struct {\n\t\tName string\n\t\tUTOff int32\n\t\tDST bool\n\t}{\n")
+	for _, t := range b.LocalTimeTypes {
+		desig, err := b.Designation(t.Idx)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&buf, "\t\t{%q, %d, %t},\n", desig, t.UTOff, t.DST != 0)
+	}
+	buf.WriteString("\t},\n")
+	buf.WriteString("\tTransitions: []struct {\n\t\tAt int64\n\t\tType uint8\n\t}{\n")
+	for i, ts := range b.TransitionTimes {
+		if i >= len(b.TransitionTypes) {
+			return nil, fmt.Errorf("missing transition type %d", i)
+		}
+		fmt.Fprintf(&buf, "\t\t{%d, %d}, // %s\n", ts, b.TransitionTypes[i], time.Unix(ts, 0).UTC().Format(time.RFC3339))
+	}
+	buf.WriteString("\t},\n")
+	if f.V2 != nil {
+		tz, err := parseFooter(f)
+		if err != nil {
+			return nil, err
+		}
+		if tz != nil {
+			fmt.Fprintf(&buf, "\tRule: %q,\n", tz.String())
+		}
+	}
+	buf.WriteString("}\n")
+	return gofmt.Source(buf.Bytes())
+}
@@ -0,0 +1,3 @@
+module github.com/martin-sucha/tzif2text
+
+go 1.21
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/martin-sucha/tzif2text/tzif"
+)
+
+// hashMain prints the semantic digest of each file in paths, or of stdin if paths is empty,
+// in the format of sha256sum. See writeCanonical.
+func hashMain(paths []string) error {
+	if len(paths) == 0 {
+		f, err := parseInput(os.Stdin)
+		if err != nil {
+			return err
+		}
+		sum, err := semanticHash(f)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "%x  -\n", sum)
+		return nil
+	}
+	for _, path := range paths {
+		f, err := parsePath(path)
+		if err != nil {
+			return err
+		}
+		sum, err := semanticHash(f)
+		if err != nil {
+			return &inputError{name: path, err: err}
+		}
+		fmt.Fprintf(stdout, "%x  %s\n", sum, path)
+	}
+	return nil
+}
+
+// semanticHash returns the SHA-256 digest of the canonical form of f written by writeCanonical.
+func semanticHash(f *tzif.File) ([]byte, error) {
+	h := sha256.New()
+	if err := writeCanonical(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// writeCanonical writes the Canonical form of f to w, one item per line, so that files that tzif.File.Equal
+// reports equal have the same canonical form and digest. The footer is written in the normalized form of
+// TZString.String.
+func writeCanonical(w io.Writer, f *tzif.File) error {
+	c, err := f.Canonical()
+	if err != nil {
+		return err
+	}
+	describe := func(z tzif.Zone) string {
+		return fmt.Sprintf("%d %t %q", z.UTOff, z.DST, z.Name)
+	}
+	fmt.Fprintf(w, "initial %s\n", describe(c.Initial))
+	for _, t := range c.Transitions {
+		fmt.Fprintf(w, "transition %d %s\n", t.Unix, describe(t.Zone))
+	}
+	for _, ls := range c.LeapSeconds {
+		fmt.Fprintf(w, "leap %d %d\n", ls.Occur, ls.Corr)
+	}
+	if c.HasFooter {
+		footer := ""
+		if c.Footer != nil {
+			footer = c.Footer.String()
+		}
+		fmt.Fprintf(w, "footer %q\n", footer)
+	}
+	return nil
+}
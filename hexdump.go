@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// hexBytesPerLine is the number of bytes printed on one line of the hex dump.
+const hexBytesPerLine = 16
+
+// hexDumper prints the fields of a TZif file one by one, each with its offset and a label.
+type hexDumper struct {
+	data []byte
+	off  int
+}
+
+// field prints the next n bytes labelled with name and the result of describe, if not nil, and returns them.
+// If fewer than n bytes remain, it prints what is left marked as truncated and returns false.
+func (d *hexDumper) field(n int, name string, describe func(value []byte) string) ([]byte, bool) {
+	value := d.data[d.off:min(d.off+n, len(d.data))]
+	ok := len(value) == n
+	label := name
+	switch {
+	case !ok:
+		label += " (truncated)"
+	case describe != nil:
+		label += ": " + describe(value)
+	}
+	for i := 0; i == 0 || i < len(value); i += hexBytesPerLine {
+		if i > 0 {
+			label = ""
+		}
+		line := fmt.Sprintf("%08x  %-*s  %s", d.off+i, hexBytesPerLine*3-1,
+			fmt.Sprintf("% x", value[i:min(i+hexBytesPerLine, len(value))]), label)
+		fmt.Fprintln(stdout, strings.TrimRight(line, " "))
+	}
+	d.off += len(value)
+	return value, ok
+}
+
+// printHexDump prints data as an annotated hex dump with the offset, raw bytes and a label of each field.
+// The layout is derived from the header counts alone, so corrupt files are dumped up to where the data runs out.
+func printHexDump(data []byte) {
+	d := &hexDumper{data: data}
+	version, ok := d.dataBlock("v1", 4)
+	if !ok || version == 0 {
+		d.trailing()
+		return
+	}
+	if _, ok := d.dataBlock("v2+", 8); !ok {
+		return
+	}
+	rest := d.data[d.off:]
+	n := len(rest)
+	if len(rest) > 0 && rest[0] == '\n' {
+		if i := bytes.IndexByte(rest[1:], '\n'); i >= 0 {
+			n = i + 2
+		}
+	}
+	if n > 0 {
+		d.field(n, "footer", func(v []byte) string { return fmt.Sprintf("%q", v) })
+	}
+	d.trailing()
+}
+
+// trailing prints the data after the end of the file, if any.
+func (d *hexDumper) trailing() {
+	if d.off < len(d.data) {
+		d.field(len(d.data)-d.off, "trailing data", nil)
+	}
+}
+
+// dataBlock prints the header and data block named name whose times are timeSize bytes long.
+// It returns the raw version byte and whether the whole block was present.
+func (d *hexDumper) dataBlock(name string, timeSize int) (byte, bool) {
+	if _, ok := d.field(4, name+" header magic", func(v []byte) string { return fmt.Sprintf("%q", v) }); !ok {
+		return 0, false
+	}
+	v, ok := d.field(1, "version", func(v []byte) string {
+		if v[0] == 0 {
+			return "1 (NUL)"
+		}
+		return fmt.Sprintf("%q", v[0])
+	})
+	if !ok {
+		return 0, false
+	}
+	version := v[0]
+	if _, ok := d.field(15, "reserved", nil); !ok {
+		return version, false
+	}
+	var counts [6]int
+	for i, countName := range []string{"isutcnt", "isstdcnt", "leapcnt", "timecnt", "typecnt", "charcnt"} {
+		v, ok := d.field(4, countName, func(v []byte) string { return fmt.Sprint(binary.BigEndian.Uint32(v)) })
+		if !ok {
+			return version, false
+		}
+		counts[i] = int(binary.BigEndian.Uint32(v))
+	}
+	isUTCnt, isStdCnt, leapCnt, timeCnt, typeCnt, charCnt := counts[0], counts[1], counts[2], counts[3], counts[4], counts[5]
+
+	readTime := func(v []byte) int64 {
+		if timeSize == 4 {
+			return int64(int32(binary.BigEndian.Uint32(v)))
+		}
+		return int64(binary.BigEndian.Uint64(v))
+	}
+	for i := 0; i < timeCnt; i++ {
+		if _, ok := d.field(timeSize, fmt.Sprintf("transition time %d", i), func(v []byte) string { return diffTime(readTime(v)) }); !ok {
+			return version, false
+		}
+	}
+	for i := 0; i < timeCnt; i++ {
+		if _, ok := d.field(1, fmt.Sprintf("transition type %d", i), describeByte); !ok {
+			return version, false
+		}
+	}
+	for i := 0; i < typeCnt; i++ {
+		if _, ok := d.field(6, fmt.Sprintf("local time type %d", i), func(v []byte) string {
+			return fmt.Sprintf("utoff %d, dst %d, idx %d", int32(binary.BigEndian.Uint32(v[0:4])), v[4], v[5])
+		}); !ok {
+			return version, false
+		}
+	}
+	if charCnt > 0 {
+		if _, ok := d.field(charCnt, "designations", func(v []byte) string { return fmt.Sprintf("%q", v) }); !ok {
+			return version, false
+		}
+	}
+	for i := 0; i < leapCnt; i++ {
+		if _, ok := d.field(timeSize+4, fmt.Sprintf("leap second %d", i), func(v []byte) string {
+			return fmt.Sprintf("occurrence %s, correction %d", diffTime(readTime(v[:timeSize])), int32(binary.BigEndian.Uint32(v[timeSize:])))
+		}); !ok {
+			return version, false
+		}
+	}
+	for i := 0; i < isStdCnt; i++ {
+		if _, ok := d.field(1, fmt.Sprintf("std/wall indicator %d", i), describeByte); !ok {
+			return version, false
+		}
+	}
+	for i := 0; i < isUTCnt; i++ {
+		if _, ok := d.field(1, fmt.Sprintf("ut/local indicator %d", i), describeByte); !ok {
+			return version, false
+		}
+	}
+	return version, true
+}
+
+// describeByte describes a single byte field by its decimal value.
+func describeByte(v []byte) string {
+	return fmt.Sprint(v[0])
+}
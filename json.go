@@ -0,0 +1,198 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/martin-sucha/tzif2text/tzif"
+)
+
+type jsonFile struct {
+	File   string         `json:"file,omitempty"`
+	V1     jsonDataBlock  `json:"v1"`
+	V2     *jsonDataBlock `json:"v2,omitempty"`
+	Footer *string        `json:"footer,omitempty"`
+	// Unreadable and Errors are only set in best-effort mode.
+	Unreadable uint64   `json:"unreadable,omitempty"`
+	Errors     []string `json:"errors,omitempty"`
+}
+
+type jsonDataBlock struct {
+	Header          jsonHeader          `json:"header"`
+	TransitionTimes []jsonTime          `json:"transition_times"`
+	TransitionTypes []int               `json:"transition_types"`
+	LocalTimeTypes  []jsonLocalTimeType `json:"local_time_types"`
+	Designations    []string            `json:"designations"`
+	LeapSeconds     []jsonLeapSecond    `json:"leap_seconds"`
+	IsStd           []bool              `json:"is_std"`
+	IsUT            []bool              `json:"is_ut"`
+	Errors          map[string]string   `json:"errors,omitempty"`
+}
+
+type jsonHeader struct {
+	Version  uint8  `json:"version"`
+	IsUTCnt  uint32 `json:"isutcnt"`
+	IsStdCnt uint32 `json:"isstdcnt"`
+	LeapCnt  uint32 `json:"leapcnt"`
+	TimeCnt  uint32 `json:"timecnt"`
+	TypeCnt  uint32 `json:"typecnt"`
+	CharCnt  uint32 `json:"charcnt"`
+}
+
+type jsonTime struct {
+	Unix int64  `json:"unix"`
+	UTC  string `json:"utc"`
+}
+
+type jsonLocalTimeType struct {
+	UTOff int32 `json:"utoff"`
+	DST   uint8 `json:"dst"`
+	Idx   uint8 `json:"idx"`
+}
+
+type jsonLeapSecond struct {
+	Occur jsonTime `json:"occur"`
+	Corr  int32    `json:"corr"`
+}
+
+// printJSON prints the whole parsed file as a single JSON object, name identifies the input file.
+func printJSON(f *tzif.File, name string) error {
+	if name == "<stdin>" {
+		name = ""
+	}
+	return writeJSON(stdout, f, name)
+}
+
+// writeJSON writes the whole parsed file to w as a single JSON object, name identifies the input file
+// and is omitted if empty.
+func writeJSON(w io.Writer, f *tzif.File, name string) error {
+	jf := jsonFile{V1: newJSONDataBlock(&f.V1), File: name}
+	if f.V2 != nil {
+		v2 := newJSONDataBlock(f.V2)
+		jf.V2 = &v2
+		footer := string(f.Footer)
+		jf.Footer = &footer
+	}
+	jf.Unreadable = f.Unreadable
+	for _, err := range f.Errors {
+		jf.Errors = append(jf.Errors, err.Error())
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(jf)
+}
+
+func newJSONDataBlock(b *tzif.DataBlock) jsonDataBlock {
+	h := b.Header
+	jb := jsonDataBlock{
+		Header: jsonHeader{
+			Version:  h.Version,
+			IsUTCnt:  h.IsUTCnt,
+			IsStdCnt: h.IsStdCnt,
+			LeapCnt:  h.LeapCnt,
+			TimeCnt:  h.TimeCnt,
+			TypeCnt:  h.TypeCnt,
+			CharCnt:  h.CharCnt,
+		},
+		TransitionTimes: make([]jsonTime, 0, len(b.TransitionTimes)),
+		TransitionTypes: make([]int, 0, len(b.TransitionTypes)),
+		LocalTimeTypes:  make([]jsonLocalTimeType, 0, len(b.LocalTimeTypes)),
+		Designations:    splitDesignations(b.Designations),
+		LeapSeconds:     make([]jsonLeapSecond, 0, len(b.LeapSeconds)),
+		IsStd:           append([]bool{}, b.IsStd...),
+		IsUT:            append([]bool{}, b.IsUT...),
+	}
+	for _, ts := range b.TransitionTimes {
+		jb.TransitionTimes = append(jb.TransitionTimes, newJSONTime(ts))
+	}
+	for _, tt := range b.TransitionTypes {
+		jb.TransitionTypes = append(jb.TransitionTypes, int(tt))
+	}
+	for _, t := range b.LocalTimeTypes {
+		jb.LocalTimeTypes = append(jb.LocalTimeTypes, jsonLocalTimeType{UTOff: t.UTOff, DST: t.DST, Idx: t.Idx})
+	}
+	for _, ls := range b.LeapSeconds {
+		jb.LeapSeconds = append(jb.LeapSeconds, jsonLeapSecond{Occur: newJSONTime(ls.Occur), Corr: ls.Corr})
+	}
+	if len(b.Errors) > 0 {
+		jb.Errors = make(map[string]string)
+		for section, err := range b.Errors {
+			jb.Errors[string(section)] = err.Error()
+		}
+	}
+	return jb
+}
+
+func newJSONTime(ts int64) jsonTime {
+	return jsonTime{Unix: ts, UTC: time.Unix(ts, 0).UTC().Format(time.RFC3339)}
+}
+
+// splitDesignations splits the raw designations into the NUL-terminated strings they contain,
+// truncated by capDesignation.
+func splitDesignations(data []byte) []string {
+	if len(data) == 0 {
+		return []string{}
+	}
+	desigs := strings.Split(strings.TrimSuffix(string(data), "\x00"), "\x00")
+	for i, desig := range desigs {
+		desigs[i] = capDesignation(desig)
+	}
+	return desigs
+}
+
+// printJSONSchema prints the JSON Schema of the objects printed by printJSON.
+func printJSONSchema() error {
+	schema := jsonSchema(reflect.TypeOf(jsonFile{}))
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "TZif file as printed by tzif2text -json"
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(schema)
+}
+
+// jsonSchema returns the JSON Schema of the values of t as encoded by encoding/json. It is derived from the
+// types so that it cannot get out of sync with the output. Struct fields are required unless tagged
+// omitempty, and no other properties are allowed.
+func jsonSchema(t reflect.Type) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return jsonSchema(t.Elem())
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		schema := map[string]any{"type": "integer"}
+		if bits := t.Bits(); bits < 64 {
+			schema["minimum"], schema["maximum"] = -1<<(bits-1), 1<<(bits-1)-1
+		}
+		return schema
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		schema := map[string]any{"type": "integer", "minimum": 0}
+		if bits := t.Bits(); bits < 64 {
+			schema["maximum"] = uint64(1)<<bits - 1
+		}
+		return schema
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": jsonSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": jsonSchema(t.Elem())}
+	case reflect.Struct:
+		properties := make(map[string]any)
+		required := []string{}
+		for i := 0; i < t.NumField(); i++ {
+			name, opts, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+			properties[name] = jsonSchema(t.Field(i).Type)
+			if opts != "omitempty" {
+				required = append(required, name)
+			}
+		}
+		return map[string]any{"type": "object", "properties": properties, "required": required,
+			"additionalProperties": false}
+	}
+	panic(fmt.Sprintf("no JSON Schema for %v", t))
+}
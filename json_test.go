@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/martin-sucha/tzif2text/tzif"
+)
+
+// validate checks v, decoded by encoding/json, against the subset of JSON Schema used by jsonSchema.
+func validate(schema map[string]any, v any, path string) error {
+	switch schema["type"] {
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("%s: %v is not a boolean", path, v)
+		}
+	case "string":
+		if _, ok := v.(string); !ok {
+			return fmt.Errorf("%s: %v is not a string", path, v)
+		}
+	case "integer":
+		n, ok := v.(float64)
+		if !ok || n != math.Trunc(n) {
+			return fmt.Errorf("%s: %v is not an integer", path, v)
+		}
+		if lo, ok := schema["minimum"].(float64); ok && n < lo {
+			return fmt.Errorf("%s: %v is below %v", path, n, lo)
+		}
+		if hi, ok := schema["maximum"].(float64); ok && n > hi {
+			return fmt.Errorf("%s: %v is above %v", path, n, hi)
+		}
+	case "array":
+		a, ok := v.([]any)
+		if !ok {
+			return fmt.Errorf("%s: %v is not an array", path, v)
+		}
+		for i, item := range a {
+			if err := validate(schema["items"].(map[string]any), item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "object":
+		o, ok := v.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: %v is not an object", path, v)
+		}
+		required, _ := schema["required"].([]any)
+		for _, name := range required {
+			if _, ok := o[name.(string)]; !ok {
+				return fmt.Errorf("%s: missing %s", path, name)
+			}
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		for name, value := range o {
+			property, ok := properties[name].(map[string]any)
+			if !ok {
+				property, ok = schema["additionalProperties"].(map[string]any)
+			}
+			if !ok {
+				return fmt.Errorf("%s: unexpected property %s", path, name)
+			}
+			if err := validate(property, value, path+"."+name); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%s: unsupported schema type %v", path, schema["type"])
+	}
+	return nil
+}
+
+func TestJSONSchema(t *testing.T) {
+	// Round trip the schema through JSON, as a consumer would read it.
+	var schema map[string]any
+	data, err := json.Marshal(jsonSchema(reflect.TypeOf(jsonFile{})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]*tzif.File{}
+	for _, name := range []string{"prague-fat.tzif", "prague-slim.tzif", "right-utc.tzif", "sydney-slim.tzif", "tokyo-slim.tzif", "utc.tzif"} {
+		files[name] = readTestFile(t, name)
+	}
+	// A version 1 file has neither the v2 nor the footer property.
+	files["prague-fat.tzif"].V2 = nil
+	// Best-effort output of a truncated file has the errors and unreadable properties.
+	data, err = os.ReadFile("tzif/testdata/prague-slim.tzif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	truncated, err := tzif.Options{BestEffort: true}.Parse(bytes.NewReader(data[:len(data)-40]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files["truncated"] = truncated
+	for name, f := range files {
+		var buf bytes.Buffer
+		if err := writeJSON(&buf, f, name); err != nil {
+			t.Fatal(err)
+		}
+		var v any
+		if err := json.Unmarshal(buf.Bytes(), &v); err != nil {
+			t.Fatal(err)
+		}
+		if err := validate(schema, v, name); err != nil {
+			t.Error(err)
+		}
+	}
+	if len(truncated.Errors) == 0 && truncated.Unreadable == 0 {
+		t.Errorf("truncated file has no errors to validate")
+	}
+	if err := validate(schema, map[string]any{"v1": map[string]any{}, "zone": "UTC"}, "invalid"); err == nil {
+		t.Errorf("invalid object validates")
+	}
+}
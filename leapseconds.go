@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/martin-sucha/tzif2text/tzif"
+)
+
+// ntpEpochOffset is the number of seconds from the NTP epoch, 1900-01-01 UTC, to the Unix epoch.
+const ntpEpochOffset = 2208988800
+
+// initialTAIOffset is TAI−UTC from 1972-01-01, before the first leap second. TZif does not record it,
+// as the corrections only count the leap seconds.
+const initialTAIOffset = 10
+
+// printLeapSecondsList prints the leap second records of b in the format of leap-seconds.list
+// distributed by IERS and with tzdata: the NTP time from which each TAI−UTC value applies.
+// The occurrences are interpreted according to -leap-base.
+func printLeapSecondsList(b *tzif.DataBlock, name string) error {
+	records, prevCorr := leapTable(b)
+	if len(records) == 0 {
+		return fmt.Errorf("no leap second records")
+	}
+	ntp := func(utc int64) int64 { return utc + ntpEpochOffset }
+	fmt.Fprintf(stdout, "#\tLeap seconds extracted from %s by tzif2text\n", name)
+	if expires, ok := b.LeapSecondsExpire(); ok {
+		utc := expires - int64(records[len(records)-1].Corr) - leapBaseOffsets[*leapBase]
+		fmt.Fprintf(stdout, "#\tFile expires on %s\n", time.Unix(utc, 0).UTC().Format("2 January 2006"))
+		fmt.Fprintln(stdout, "#")
+		fmt.Fprintf(stdout, "#@\t%d\n", ntp(utc))
+	}
+	fmt.Fprintln(stdout, "#")
+	if prevCorr == 0 {
+		start := time.Date(1972, time.January, 1, 0, 0, 0, 0, time.UTC)
+		fmt.Fprintf(stdout, "%-16d%-8d# %s\n", ntp(start.Unix()), initialTAIOffset, start.Format("2 Jan 2006"))
+	}
+	for _, ls := range records {
+		// Midnight UTC following the leap second, from which the new value applies.
+		utc := ls.Occur - int64(prevCorr) - leapBaseOffsets[*leapBase]
+		fmt.Fprintf(stdout, "%-16d%-8d# %s\n", ntp(utc), initialTAIOffset+ls.Corr, time.Unix(utc, 0).UTC().Format("2 Jan 2006"))
+		prevCorr = ls.Corr
+	}
+	return nil
+}
@@ -3,253 +3,1646 @@ package main
 
 import (
 	"bytes"
-	"encoding/binary"
+	"compress/gzip"
+	"encoding/hex"
+	"flag"
 	"fmt"
 	"io"
+	"io/fs"
+	"math"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/martin-sucha/tzif2text/tzif"
+)
+
+// stdout and stderr receive the output and the warnings and errors of the input being printed. Batch
+// workers point them at the buffers of their input while printing it, see processBatch.
+var stdout, stderr io.Writer = os.Stdout, os.Stderr
+
+var (
+	printFooterHex = flag.Bool("print-footer-hex", false, "print the raw footer bytes as an annotated hex dump")
+	bestEffort     = flag.Bool("best-effort", false, "continue with the next section after a malformed data block section, decode truncated files as far as they go and report the unreadable bytes")
+	listZones      = flag.Bool("list-zones", false, "list the zones found in $TZDIR (default /usr/share/zoneinfo), or the members of the -zip archive")
+	zoneName       = flag.String("zone", "", "read the zone `NAME` from $TZDIR (default /usr/share/zoneinfo), or from the -zip archive, in addition to the arguments")
+	fromEnv        = flag.Bool("from-env", false, "explain the POSIX TZ string in the TZ environment variable instead of reading a file")
+	zipPath        = flag.String("zip", "", "read the inputs as members of the zip archive `FILE`, such as $GOROOT/lib/time/zoneinfo.zip or a Go executable embedding time/tzdata")
+	summary        = flag.Bool("summary", false, "print a one-line summary of each input, or of each zone with -list-zones, instead of dumping it")
+	summaryTable   = flag.Bool("summary-table", false, "print a table of the version, counts, years of the first and last transition and fat/slim format of the inputs sorted by name instead of dumping them; aliases are left out")
+	leapBase       = flag.String("leap-base", "utc", "time scale of leap second occurrences: utc or tai")
+	radix          = flag.Int("radix", 10, "base of printed numeric values: 10 or 16")
+	progressBar    = flag.Bool("progress-bar", false, "show progress on stderr while printing large transition tables")
+	magicOnly      = flag.Bool("validate-magic-only", false, "only check the magic and version bytes and print the version")
+	printTZ        = flag.Bool("print-tz", false, "only print the footer TZ string and its fields, reading just the headers and the footer of seekable inputs")
+	genPackage     = flag.String("package", "tzdata", "package name of the source printed by the gen subcommand")
+	genVar         = flag.String("var", "Zone", "variable name of the zone in the source printed by the gen subcommand")
+	analyzeFlag    = flag.Bool("analyze", false, "report suspicious content such as unusual designations, duplicate types or negative DST instead of dumping the file")
+	serveAddr      = flag.String("serve", "", "serve JSON over HTTP on `ADDR`: POST a file to /parse or GET /zones/NAME from the zoneinfo directory given as argument, $TZDIR or /usr/share/zoneinfo")
+	redundant      = flag.Bool("redundant", false, "list the transitions generated by the footer TZ string and the size of a slim re-encoding instead of dumping the file")
+	verifyFooterN  = flag.Int("verify-footer-consistency", 0, "check that the footer TZ string projected over the years of the last `N` transitions reproduces them instead of dumping the file, reporting the mismatches and the first mismatching year; exit status is 1 if there are any")
+	verifyStd      = flag.Bool("verify-stdlib", false, "compare the interpretation of the file with Go's time package around every change of local time instead of dumping it")
+	cArray         = flag.Bool("c-array", false, "print the input as a C array initializer instead of dumping it")
+	cName          = flag.String("c-name", "tzif_data", "name of the array printed by -c-array")
+	check          = flag.Bool("check", false, "list all RFC 8536 violations instead of dumping the file, exit status is 1 if there are any")
+	strict         = flag.Bool("strict", false, "reject files violating RFC 8536 requirements on headers and transition order")
+	maxCount       = flag.Uint("max-count", 0, "reject files with any header count above `N`, 0 means no limit")
+	maxSize        = flag.Int64("max-size", 0, "reject inputs longer than `BYTES`, 0 means no limit")
+	lenient        = flag.Bool("lenient", false, "warn about violations that do not prevent decoding the rest of the file, such as out of range indexes, instead of rejecting the file")
+	tmplText       = flag.String("template", "", "print the output of the text/template `TEXT` executed with the tzif.View of each input instead of dumping it")
+	errorFormat    = flag.String("error-format", "text", "format of errors printed to stderr: text, or json for one object per error with the offset, field and expected and actual values of parse errors; the exit status is 1 for validation failures and other errors, 3 for malformed input and 4 for I/O errors")
+	color          = flag.String("color", "auto", "colorize and align the output: auto (if writing to a terminal), always or never")
+	format         = flag.String("format", "text", "output format: text, json, csv, tsv, hex (annotated hex dump), zdump (like zdump -v), zdump-V (like zdump -V) or vtimezone (iCalendar VTIMEZONE)")
+	jsonOutput     = flag.Bool("json", false, "print the parsed file as a JSON object")
+	jsonSchemaOut  = flag.Bool("json-schema", false, "print the JSON Schema of the objects printed by -json and exit")
+	timeFormat     = flag.String("time-format", defaultTimeLayout, "Go time `LAYOUT` of printed transition times, or rfc3339")
+	unixOnly       = flag.Bool("unix-only", false, "print transition times only as Unix time, without the UTC and local time")
+	localTimes     = flag.Bool("local", false, "print transition times in the local time in effect before the transition instead of UTC")
+	utcOnly        = flag.Bool("utc-only", false, "print times only in UTC, without the local times after transitions and at -at; of the time rendering flags, -unix-only takes precedence over -utc-only, which takes precedence over -local")
+	from           = flag.String("from", "", "print only transitions and leap seconds at or after `TIME`, given as RFC 3339 or a year; the truncate subcommand drops the transitions before it")
+	until          = flag.String("until", "", "print only transitions and leap seconds before `TIME`, given as RFC 3339 or a year to include all of it; the truncate subcommand drops the transitions at or after it and leaves local time from it on unspecified")
+	leapList       = flag.Bool("leap-seconds-list", false, "print the leap second records in the format of leap-seconds.list instead of dumping the file")
+	noResolve      = flag.Bool("no-resolve", false, "keep the dump to the raw indexes: print transitions without the local time type they switch to and local time type records without the designation idx refers to, times are printed in UTC")
+	annotateEvents = flag.Bool("annotate-known-events", false, "annotate the transitions of well-known changes of the DST rules, such as the EU harmonization of 1996, in the -resolve output")
+	resolve        = flag.Bool("resolve", false, "print each transition joined with the local time types before and after it instead of dumping the file")
+	at             = flag.String("at", "", "print the local time in effect at `TIMESTAMP`, given as Unix time or RFC 3339, instead of dumping the file")
+	csvOutput      = flag.Bool("csv", false, "print the transitions as a CSV table, or the local time type records with -section types")
+	jobs           = flag.Int("jobs", 1, "read up to `N` inputs concurrently, printing the results in order followed by the number of inputs that passed and failed")
+	recursive      = flag.Bool("recursive", false, "dump all TZif files under directories given as arguments, symbolic links are reported as aliases")
+	againstEmbed   = flag.Bool("against-embedded", false, "compare the input with the embedded tzdata 2025b zone its path ends with, such as Europe/Prague, and print the differences as -diff does instead of dumping it; exit status is 1 if they differ")
+	compareRel     = flag.Bool("compare-tzdata-release", false, "list the zones added (+), removed (-) and changed (~) between the two tarballs of compiled zones given as arguments, exit status is 1 if there are any")
+	diffFile       = flag.String("diff", "", "compare `FILE` with the input and print the differences, exit status is 1 if they differ")
+	diffJSON       = flag.Bool("diff-json", false, "print the differences found by -diff or the diff subcommand as a JSON object with the added, removed and changed transitions, types and leap seconds and the footer change")
+	maxDesigLen    = flag.Int("max-desig-len", 16, "truncate and warn about time zone designations longer than this many bytes (negative disables)")
 )
 
+func init() {
+	flag.Var(sections, "section", "print only the named `section` (header, transitions, types, designations, leap, indicators or footer), may be repeated")
+}
+
 func main() {
+	flag.Parse()
 	err := mainErr()
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
+	if err == errDiffer {
 		os.Exit(1)
 	}
+	if err != nil {
+		printError(err)
+		os.Exit(exitStatus(err))
+	}
 }
 
 func mainErr() error {
-	data, err := io.ReadAll(os.Stdin)
-	if err != nil {
+	if err := setupColor(*color); err != nil {
 		return err
 	}
-	data, h, err := parseHeader(data)
+	if _, ok := leapBaseOffsets[*leapBase]; !ok {
+		return fmt.Errorf("unsupported -leap-base: %q", *leapBase)
+	}
+	if *radix != 10 && *radix != 16 {
+		return fmt.Errorf("unsupported -radix: %d", *radix)
+	}
+	timeLayout = *timeFormat
+	if strings.EqualFold(timeLayout, "rfc3339") {
+		timeLayout = time.RFC3339
+	}
+	if *jobs < 1 {
+		return fmt.Errorf("invalid -jobs: %d, must be at least 1", *jobs)
+	}
+	if *errorFormat != "text" && *errorFormat != "json" {
+		return fmt.Errorf("unsupported -error-format: %q", *errorFormat)
+	}
+	if *annotateEvents && !*resolve {
+		return fmt.Errorf("-annotate-known-events annotates the output of -resolve")
+	}
+	if *noResolve && (*resolve || *localTimes) {
+		return fmt.Errorf("-no-resolve excludes -resolve and -local, which need the indexes resolved")
+	}
+	if *strict && *lenient {
+		return fmt.Errorf("-strict and -lenient are mutually exclusive")
+	}
+	var err error
+	fromTime, err = parseBound(*from, false)
+	if err != nil {
+		return fmt.Errorf("invalid -from: %v", err)
+	}
+	untilTime, err = parseBound(*until, true)
+	if err != nil {
+		return fmt.Errorf("invalid -until: %v", err)
+	}
+	if *tmplText != "" {
+		outputTemplate, err = parseTemplate(*tmplText)
+		if err != nil {
+			return fmt.Errorf("invalid -template: %v", err)
+		}
+	}
+	switch *format {
+	case "text":
+	case "json":
+		*jsonOutput = true
+	case "csv":
+		*csvOutput = true
+	case "tsv", "hex", "zdump", "zdump-V", "vtimezone":
+	default:
+		return fmt.Errorf("unsupported -format: %q", *format)
+	}
+	if *verifyFooterN < 0 {
+		return fmt.Errorf("invalid -verify-footer-consistency: %d, must not be negative", *verifyFooterN)
+	}
+	if *jsonSchemaOut {
+		return printJSONSchema()
+	}
+	if *csvOutput || *format == "tsv" {
+		if err := checkCSVSections(); err != nil {
+			return err
+		}
+	}
+	if *fromEnv {
+		return printTZEnv()
+	}
+	if *serveAddr != "" {
+		if flag.NArg() > 1 {
+			return fmt.Errorf("-serve takes at most one zoneinfo directory")
+		}
+		return serve(*serveAddr, zoneinfoDir(flag.Arg(0)))
+	}
+	if *zipPath != "" {
+		z, err := openZip(*zipPath)
+		if err != nil {
+			return err
+		}
+		if *listZones {
+			return listZip(z)
+		}
+		openInput = func(name string) (io.ReadCloser, error) {
+			return z.Open(name)
+		}
+	}
+	if *listZones {
+		if flag.NArg() > 1 {
+			return fmt.Errorf("-list-zones takes at most one directory")
+		}
+		return listTZDir(flag.Arg(0))
+	}
+	if *compareRel {
+		if flag.NArg() != 2 {
+			return fmt.Errorf("-compare-tzdata-release compares two tarballs, got %d", flag.NArg())
+		}
+		return compareReleases(flag.Arg(0), flag.Arg(1))
+	}
+	if *diffFile != "" {
+		if flag.NArg() > 1 {
+			return fmt.Errorf("-diff compares a single input, got %d", flag.NArg())
+		}
+		return diffMain(*diffFile, flag.Arg(0))
+	}
+	if flag.NArg() == 3 && flag.Arg(0) == "diff" {
+		return diffMain(flag.Arg(1), flag.Arg(2))
+	}
+	if flag.NArg() == 3 && flag.Arg(0) == "query" {
+		f, err := parsePath(flag.Arg(1))
+		if err != nil {
+			return err
+		}
+		return printAt(f, flag.Arg(2))
+	}
+	if (flag.NArg() == 2 || flag.NArg() == 3) && flag.Arg(0) == "convert" {
+		return convertMain(flag.Arg(1), flag.Arg(2))
+	}
+	if (flag.NArg() == 1 || flag.NArg() == 2) && flag.Arg(0) == "gen" {
+		return genMain(flag.Arg(1))
+	}
+	if (flag.NArg() == 1 || flag.NArg() == 2) && flag.Arg(0) == "assemble" {
+		return assembleMain(flag.Arg(1))
+	}
+	if (flag.NArg() == 1 || flag.NArg() == 2) && flag.Arg(0) == "truncate" {
+		return truncateMain(flag.Arg(1))
+	}
+	if (flag.NArg() == 1 || flag.NArg() == 2) && flag.Arg(0) == "normalize" {
+		return normalizeMain(flag.Arg(1))
+	}
+	if flag.NArg() >= 1 && flag.Arg(0) == "hash" {
+		return hashMain(flag.Args()[1:])
+	}
+	args := flag.Args()
+	if *zoneName != "" {
+		// The members of zip archives are named by the zone.
+		if *zipPath == "" {
+			args = append(args, filepath.Join(zoneinfoDir(""), *zoneName))
+		} else {
+			args = append(args, *zoneName)
+		}
+	}
+	if *summaryTable {
+		// Printed once all inputs are read, since the rows are sorted.
+		defer printSummaryTable()
+	}
+	if len(args) == 0 {
+		return processInput(os.Stdin, "<stdin>")
+	}
+	inputs := make([]zoneEntry, 0, len(args))
+	for _, path := range args {
+		if *recursive && *zipPath == "" {
+			fi, err := os.Stat(path)
+			if err == nil && fi.IsDir() {
+				zones, err := walkZones(path)
+				if err != nil {
+					return err
+				}
+				for _, zone := range zones {
+					zone.name = filepath.Join(path, zone.name)
+					inputs = append(inputs, zone)
+				}
+				continue
+			}
+		}
+		inputs = append(inputs, zoneEntry{name: path})
+	}
+	if len(inputs) == 1 && inputs[0].alias == "" {
+		return processFile(inputs[0].name)
+	}
+	var results []chan *batchResult
+	if *jobs > 1 {
+		results = processBatch(inputs, *jobs)
+	}
+	failed, status := 0, 0
+	for i, input := range inputs {
+		var res *batchResult
+		if results != nil && input.alias == "" {
+			res = <-results[i]
+		}
+		printMu.Lock()
+		err := printListed(i, input, res)
+		printMu.Unlock()
+		if err != nil {
+			failed++
+			status = max(status, exitStatus(err))
+		}
+	}
+	if *jobs > 1 {
+		fmt.Fprintf(os.Stderr, "%d passed, %d failed\n", len(inputs)-failed, failed)
+	}
+	if failed > 0 {
+		return &inputsFailedError{failed: failed, total: len(inputs), status: status}
+	}
+	return nil
+}
+
+// fromTime and untilTime are the bounds set by -from and -until.
+var fromTime, untilTime int64 = math.MinInt64, math.MaxInt64
+
+// parseBound parses a -from or -until value, an empty value is unbounded. A year is the start of the year,
+// or the start of the following year if end is set, so that -until includes it.
+func parseBound(s string, end bool) (int64, error) {
+	if s == "" {
+		if end {
+			return math.MaxInt64, nil
+		}
+		return math.MinInt64, nil
+	}
+	if year, err := strconv.Atoi(s); err == nil {
+		if end {
+			year++
+		}
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC).Unix(), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is neither RFC 3339 nor a year", s)
+	}
+	return t.Unix(), nil
+}
+
+// inRange reports whether t is within -from and -until.
+func inRange(t int64) bool {
+	return fromTime <= t && t < untilTime
+}
+
+// printListed prints inputs[i] of the inputs given on the command line under a heading, res is its result
+// if it was processed by a batch worker. It prints and returns the error processing the input, if any.
+func printListed(i int, input zoneEntry, res *batchResult) error {
+	text := textOutput()
+	if text {
+		if i > 0 {
+			fmt.Fprintln(stdout)
+		}
+		printHeading("File: " + input.name)
+	}
+	if input.alias != "" {
+		// Aliases are dumped once, under the name of the zone they link to.
+		if text {
+			fmt.Fprintf(stdout, "Alias of: %s\n", input.alias)
+		}
+		if *summary {
+			fmt.Fprintf(stdout, "%s -> %s\n", input.name, input.alias)
+		}
+		return nil
+	}
+	var err error
+	if res != nil {
+		stdout.Write(res.stdout.Bytes())
+		stderr.Write(res.stderr.Bytes())
+		err = res.err
+	} else {
+		err = processFile(input.name)
+	}
+	if err != nil {
+		printError(err)
+	}
+	return err
+}
+
+func processFile(path string) error {
+	f, err := openInput(path)
 	if err != nil {
 		return err
 	}
-	printHeader(h)
-	data, err = printDataBlock(data, h, time32)
+	in := loadInput(f)
+	f.Close()
+	err = printInput(in, path)
+	if err != nil {
+		return &inputError{name: path, err: err}
+	}
+	return nil
+}
+
+// processInput prints the TZif data read from r, name identifies the input in the output.
+func processInput(r io.Reader, name string) error {
+	return printInput(loadInput(r), name)
+}
+
+// loadedInput is an input read and parsed by loadInput, to be printed by printInput.
+type loadedInput struct {
+	// err is the error reading the input.
+	err error
+	// version is the version read with -validate-magic-only, which reads nothing else.
+	version uint8
+	// footer is the footer read with -print-tz, which reads nothing else.
+	footer []byte
+	// entries are the TZif files concatenated in the input, see tzif.Split.
+	entries []loadedEntry
+}
+
+// loadedEntry is one of the TZif files of a loadedInput.
+type loadedEntry struct {
+	data []byte
+	// f and err are the result of parsing data, both are nil in the modes that read data themselves.
+	f   *tzif.File
+	err error
+	// violations are the RFC 8536 violations of data found with -check.
+	violations []string
+}
+
+// loadInput reads the input from r and parses the TZif files in it as the selected mode needs, without
+// printing anything, so that batch workers can load inputs concurrently.
+func loadInput(r io.Reader) *loadedInput {
+	r, err := gunzip(r)
+	if err != nil {
+		return &loadedInput{err: err}
+	}
+	if *magicOnly {
+		version, err := tzif.ReadVersion(r)
+		return &loadedInput{version: version, err: err}
+	}
+	if *printTZ {
+		footer, err := parseOptions().ReadFooter(r)
+		return &loadedInput{footer: footer, err: err}
+	}
+	// The whole input is read to find the files concatenated in it. The parser still enforces -max-size,
+	// reading one byte past the limit is enough for it to notice.
+	if *maxSize > 0 {
+		r = io.LimitReader(r, *maxSize+1)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return &loadedInput{err: err}
+	}
+	entries := tzif.Split(data)
+	if len(entries) == 1 {
+		entries[0] = data
+	}
+	in := &loadedInput{}
+	for _, entry := range entries {
+		e := loadedEntry{data: entry}
+		switch {
+		case *check:
+			e.violations = checkViolations(entry)
+		case *format == "hex":
+		default:
+			e.f, e.err = parseOptions().Parse(bytes.NewReader(entry))
+		}
+		in.entries = append(in.entries, e)
+	}
+	return in
+}
+
+// printInput prints in, the input called name. If it holds several concatenated TZif files, each is
+// printed as an entry named by its index.
+func printInput(in *loadedInput, name string) error {
+	if in.err != nil {
+		return in.err
+	}
+	if *magicOnly {
+		fmt.Fprintln(stdout, "version:", in.version)
+		return nil
+	}
+	if *printTZ {
+		return printFooterTZ(in.footer)
+	}
+	if len(in.entries) == 1 {
+		return processData(in.entries[0], name)
+	}
+	failed, status := 0, 0
+	for i, entry := range in.entries {
+		if textOutput() {
+			if i > 0 {
+				fmt.Fprintln(stdout)
+			}
+			printHeading(fmt.Sprintf("Entry %d:", i))
+		}
+		entryName := fmt.Sprintf("%s[%d]", name, i)
+		if err := processData(entry, entryName); err != nil {
+			err = &inputError{name: entryName, err: err}
+			printError(err)
+			failed++
+			status = max(status, exitStatus(err))
+		}
+	}
+	if failed > 0 {
+		return &inputsFailedError{failed: failed, total: len(in.entries), status: status}
+	}
+	return nil
+}
+
+// textOutput reports whether the selected mode prints the text dump, which gets headings per input.
+func textOutput() bool {
+	return (*format == "text" || *format == "hex") && !*jsonOutput && !*csvOutput && !*check && !*verifyStd && !*leapList && !*summary && !*summaryTable && !*analyzeFlag && !*redundant && *verifyFooterN == 0 && outputTemplate == nil
+}
+
+// processData prints entry, a single TZif file read from the input called name, in the selected mode.
+func processData(entry loadedEntry, name string) error {
+	data := entry.data
+	if *check {
+		return printViolations(entry.violations, name)
+	}
+	if *format == "hex" {
+		printHexDump(data)
+		return nil
+	}
+	f, err := entry.f, entry.err
 	if err != nil {
 		return err
 	}
-	if h.version > 1 {
-		var h2 header
-		data, h2, err = parseHeader(data)
+	printWarnings(f)
+	if *verifyStd {
+		return verifyStdlib(data, f, name)
+	}
+	if *summary {
+		fmt.Fprintln(stdout, summaryLine(name, f, len(data)))
+		return nil
+	}
+	if *summaryTable {
+		addSummaryRow(name, f)
+		return nil
+	}
+	if *analyzeFlag {
+		return analyze(f, name)
+	}
+	if *redundant {
+		return printRedundant(f, len(data))
+	}
+	if *verifyFooterN > 0 {
+		return verifyFooter(f, *verifyFooterN, name)
+	}
+	if *againstEmbed {
+		return diffEmbedded(f, name)
+	}
+	if outputTemplate != nil {
+		return executeTemplate(f, name)
+	}
+	if *cArray {
+		printCArray(data, name, *cName)
+		return nil
+	}
+	if *format == "zdump" || *format == "zdump-V" {
+		err = printZdump(f, name, *format == "zdump")
+		if err != nil {
+			return err
+		}
+	} else if *format == "vtimezone" {
+		err = printVtimezone(f, name)
+		if err != nil {
+			return err
+		}
+	} else if *leapList {
+		err = printLeapSecondsList(lastBlock(f), name)
+		if err != nil {
+			return err
+		}
+	} else if *resolve {
+		err = printResolved(lastBlock(f), name)
+		if err != nil {
+			return err
+		}
+	} else if *at != "" {
+		err = printAt(f, *at)
+		if err != nil {
+			return err
+		}
+	} else if *csvOutput || *format == "tsv" {
+		comma := ','
+		if *format == "tsv" {
+			comma = '\t'
+		}
+		err = printCSV(f, comma)
+		if err != nil {
+			return err
+		}
+	} else if *jsonOutput {
+		err = printJSON(f, name)
 		if err != nil {
 			return err
 		}
-		printHeader(h2)
-		data, err = printDataBlock(data, h2, time64)
+	} else {
+		err = printFile(f)
 		if err != nil {
 			return err
 		}
-		fmt.Printf("Footer:\n%q\n", data)
 	}
+	recoveredSections := len(f.V1.Errors) + len(f.Errors)
+	if f.V2 != nil {
+		recoveredSections += len(f.V2.Errors)
+	}
+	if recoveredSections > 0 {
+		if f.Unreadable > 0 {
+			return fmt.Errorf("%d section(s) could not be decoded, %d bytes unreadable", recoveredSections, f.Unreadable)
+		}
+		return fmt.Errorf("%d section(s) could not be decoded", recoveredSections)
+	}
+	return nil
+}
+
+// gunzip returns a reader decompressing r if r starts with the gzip magic, or a reader equivalent to r otherwise.
+func gunzip(r io.Reader) (io.Reader, error) {
+	var sniff [2]byte
+	n, err := io.ReadFull(r, sniff[:])
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	if sniff != [2]byte{0x1f, 0x8b} {
+		// Seekable inputs stay seekable for -print-tz, which skips the data blocks.
+		if rs, ok := r.(io.ReadSeeker); ok {
+			if _, err := rs.Seek(-int64(n), io.SeekCurrent); err == nil {
+				return rs, nil
+			}
+		}
+		return io.MultiReader(bytes.NewReader(sniff[:n]), r), nil
+	}
+	r = io.MultiReader(bytes.NewReader(sniff[:n]), r)
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("gzip decompression failed: %v", err)
+	}
+	return gzipReader{zr}, nil
+}
+
+// gzipReader marks decompression errors so that they are not mistaken for TZif parse errors.
+type gzipReader struct {
+	zr *gzip.Reader
+}
+
+func (g gzipReader) Read(p []byte) (int, error) {
+	n, err := g.zr.Read(p)
+	if err != nil && err != io.EOF {
+		err = fmt.Errorf("gzip decompression failed: %v", err)
+	}
+	return n, err
+}
+
+func printFile(f *tzif.File) error {
+	var footerErr error
+	printDataBlock(&f.V1)
+	block := &f.V1
+	if f.V2 != nil {
+		printDataBlock(f.V2)
+		block = f.V2
+		if *strict {
+			if err := f.CheckHeaders(); err != nil {
+				return err
+			}
+		}
+		// A missing footer is reported with the other errors of f.
+		if showSection("footer") && f.Footer != nil {
+			printHeading("Footer:")
+			fmt.Fprintf(stdout, "%q\n", f.Footer)
+			if *printFooterHex {
+				printFooterHexDump(f.Footer)
+			}
+			tz, err := parseFooter(f)
+			if err != nil && !*bestEffort {
+				return err
+			}
+			if err != nil {
+				// The rest of the file is still printed, the error is returned at the end.
+				fmt.Fprintf(stdout, " error: %v\n", err)
+				footerErr = err
+			} else {
+				printTZString(tz)
+			}
+		}
+	}
+	if showSection("header") {
+		printFormat(f)
+	}
+	warnStale(block, f.Footer)
+	if len(f.Errors) > 0 || f.Unreadable > 0 {
+		printHeading("Unreadable:")
+		for _, err := range f.Errors {
+			fmt.Fprintf(stdout, " error: %v\n", err)
+		}
+		fmt.Fprintf(stdout, " %d bytes described by the headers could not be read\n", f.Unreadable)
+	}
+	return footerErr
+}
+
+// printFooterTZ prints the TZ string of footer, read by -print-tz, and its fields.
+func printFooterTZ(footer []byte) error {
+	if footer == nil {
+		return fmt.Errorf("version 1 files have no footer")
+	}
+	tz, err := tzif.ParseFooter(footer)
+	if err != nil {
+		return fmt.Errorf("footer: %v", err)
+	}
+	if tz == nil {
+		fmt.Fprintln(stdout, `""`)
+	} else {
+		fmt.Fprintf(stdout, "%q\n", tz.String())
+	}
+	printTZString(tz)
+	return nil
+}
+
+// parseFooter parses the TZ string in the footer of f. Errors are reported as *tzif.ParseError at the
+// offset of the footer, so that they are classified as malformed input like the other parse errors.
+func parseFooter(f *tzif.File) (*tzif.TZString, error) {
+	tz, err := tzif.ParseFooter(f.Footer)
+	if err != nil {
+		return nil, &tzif.ParseError{Offset: f.FooterOffset(), Field: "footer", Err: err}
+	}
+	return tz, nil
+}
+
+// printAt prints the local time in effect at timestamp, which is Unix time or RFC 3339.
+func printAt(f *tzif.File, timestamp string) error {
+	t, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		tm, err := time.Parse(time.RFC3339, timestamp)
+		if err != nil {
+			return fmt.Errorf("invalid -at %q: must be Unix time or RFC 3339", timestamp)
+		}
+		t = tm.Unix()
+	}
+	z, err := f.Lookup(t)
+	if err != nil {
+		return err
+	}
+	kind := "std"
+	if z.DST {
+		kind = "dst"
+	}
+	source := "transition table"
+	if z.FromFooter {
+		source = "footer TZ string"
+	}
+	utc := time.Unix(t, 0).UTC()
+	name := capDesignation(z.Name)
+	if *utcOnly {
+		fmt.Fprintf(stdout, "%s (%s UTC) -> %s, %s, from %s\n", num(t), utc.Format(timeLayout),
+			zoneLabel(name, z.UTOff), kind, source)
+		return nil
+	}
+	fmt.Fprintf(stdout, "%s (%s UTC) -> %s %s, %s, from %s\n", num(t), utc.Format(timeLayout),
+		utc.In(time.FixedZone(name, int(z.UTOff))).Format(timeLayout), zoneLabel(name, z.UTOff), kind, source)
 	return nil
 }
 
-func time32(data []byte) ([]byte, int64, error) {
-	if len(data) < 4 {
-		return data, 0, fmt.Errorf("missing time32 data")
+// printFormat prints whether the file is slim, fat or version 1 only.
+func printFormat(f *tzif.File) {
+	if f.V2 == nil && f.V1.Header.Version > 1 {
+		fmt.Fprintf(stdout, "Format: unknown, v2+ data block missing\n")
+		return
+	}
+	if f.V2 == nil {
+		fmt.Fprintf(stdout, "Format: %s\n", tzif.Classify(f.V1.Header, nil))
+		return
 	}
-	value := int32(binary.BigEndian.Uint32(data[0:4]))
-	return data[4:], int64(value), nil
+	format := tzif.Classify(f.V1.Header, &f.V2.Header)
+	if string(f.Footer) == "\n\n" {
+		fmt.Fprintf(stdout, "Format: %s, footer has no TZ string\n", format)
+		return
+	}
+	fmt.Fprintf(stdout, "Format: %s\n", format)
 }
 
-func time64(data []byte) ([]byte, int64, error) {
-	if len(data) < 8 {
-		return data, 0, fmt.Errorf("missing time64 data")
+// printTZString prints the fields of the TZ string from the footer.
+func printTZString(tz *tzif.TZString) {
+	if tz == nil {
+		fmt.Fprintln(stdout, " (empty TZ string, no rule after the last transition)")
+		return
+	}
+	fmt.Fprintf(stdout, " std: %q utoff=%s\n", tz.Std, num(int64(tz.StdOff)))
+	if tz.DST != "" {
+		fmt.Fprintf(stdout, " dst: %q utoff=%s\n", tz.DST, num(int64(tz.DSTOff)))
+		if tz.Start != nil {
+			fmt.Fprintf(stdout, " start: %s\n", formatRule(*tz.Start))
+			fmt.Fprintf(stdout, " end: %s\n", formatRule(*tz.End))
+		}
 	}
-	value := int64(binary.BigEndian.Uint64(data[0:8]))
-	return data[8:], value, nil
+	fmt.Fprintf(stdout, " explanation: %s\n", explainTZString(tz))
 }
 
-type header struct {
-	version byte
-	isutcnt, isstdcnt, leapcnt, timecnt, typecnt, charcnt uint32
+// explainTZString describes the rule of the TZ string in words.
+func explainTZString(tz *tzif.TZString) string {
+	std := zoneLabel(tz.Std, tz.StdOff)
+	if tz.DST == "" {
+		return std + " all year"
+	}
+	dst := zoneLabel(tz.DST, tz.DSTOff)
+	if tz.Start == nil {
+		return fmt.Sprintf("%s, daylight saving time %s without rules, the transitions are implementation-defined", std, dst)
+	}
+	s := fmt.Sprintf("%s from %s at %s standard time to %s at %s daylight saving time, %s otherwise",
+		dst, explainRuleDay(*tz.Start), formatClock(tz.Start.Time), explainRuleDay(*tz.End), formatClock(tz.End.Time), std)
+	if tz.DSTOff < tz.StdOff {
+		s += "; negative DST, daylight saving time is behind standard time"
+	}
+	return s
 }
 
-func printHeader(h header) {
-	fmt.Println("Header:")
-	fmt.Println(" version:", h.version)
-	fmt.Printf(" isutcnt: %d\n", h.isutcnt)
-	fmt.Printf(" isstdcnt: %d\n", h.isstdcnt)
-	fmt.Printf(" leapcnt: %d\n", h.leapcnt)
-	fmt.Printf(" timecnt: %d\n", h.timecnt)
-	fmt.Printf(" typecnt: %d\n", h.typecnt)
-	fmt.Printf(" charcnt: %d\n", h.charcnt)
+var ordinals = []string{"first", "second", "third", "fourth", "last"}
+
+// explainRuleDay describes the day a rule selects.
+func explainRuleDay(r tzif.Rule) string {
+	switch r.Kind {
+	case tzif.RuleJulian:
+		// February 29 is never counted, so the day falls on the same date as in a non-leap year.
+		return time.Date(2001, time.January, r.Day, 0, 0, 0, 0, time.UTC).Format("January 2")
+	case tzif.RuleZeroJulian:
+		return fmt.Sprintf("day %d of the year counting from 0", r.Day)
+	default:
+		return fmt.Sprintf("the %s %s of %s", ordinals[r.Week-1], time.Weekday(r.Weekday), time.Month(r.Month))
+	}
 }
 
-func parseHeader(data []byte) ([]byte, header, error) {
-	var h header
-	// magic
-	if len(data) < 4 || !bytes.Equal(data[0:4], []byte{0x54, 0x5A, 0x69, 0x66}) {
-		return data, h, fmt.Errorf("invalid header")
+// formatClock formats a rule time in seconds since midnight as [-]hh:mm[:ss].
+// Version 3 rule times may be negative or exceed 24 hours.
+func formatClock(secs int32) string {
+	sign := ""
+	if secs < 0 {
+		sign = "-"
+		secs = -secs
 	}
-	data = data[4:]
-	// version
-	if len(data) < 1 {
-		return data, h, fmt.Errorf("missing version")
+	if secs%60 != 0 {
+		return fmt.Sprintf("%s%02d:%02d:%02d", sign, secs/3600, secs/60%60, secs%60)
 	}
-	switch data[0] {
-	case 0:
-		h.version = 1
-	case 0x32:
-		h.version = 2
-	case 0x33:
-		h.version = 3
+	return fmt.Sprintf("%s%02d:%02d", sign, secs/3600, secs/60%60)
+}
+
+func formatRule(r tzif.Rule) string {
+	switch r.Kind {
+	case tzif.RuleJulian:
+		return fmt.Sprintf("julian day=%s time=%s", num(int64(r.Day)), num(int64(r.Time)))
+	case tzif.RuleZeroJulian:
+		return fmt.Sprintf("zero-based day=%s time=%s", num(int64(r.Day)), num(int64(r.Time)))
 	default:
-		return data, h, fmt.Errorf("unsupported version: %d", data[0])
-	}
-	data = data[1:]
-	// unused
-	if len(data) < 15 {
-		return data, h, fmt.Errorf("missing unused")
-	}
-	data = data[15:]
-	// isutcnt
-	if len(data) < 4 {
-		return data, h, fmt.Errorf("missing isutcnt")
-	}
-	h.isutcnt = binary.BigEndian.Uint32(data[:4])
-	data = data[4:]
-	// isstdcnt
-	if len(data) < 4 {
-		return data, h, fmt.Errorf("missing isstdcnt")
-	}
-	h.isstdcnt = binary.BigEndian.Uint32(data[:4])
-	data = data[4:]
-	// leapcnt
-	if len(data) < 4 {
-		return data, h, fmt.Errorf("missing leapcnt")
-	}
-	h.leapcnt = binary.BigEndian.Uint32(data[:4])
-	data = data[4:]
-	// timecnt
-	if len(data) < 4 {
-		return data, h, fmt.Errorf("missing timecnt")
-	}
-	h.timecnt = binary.BigEndian.Uint32(data[:4])
-	data = data[4:]
-	// typecnt
-	if len(data) < 4 {
-		return data, h, fmt.Errorf("missing typecnt")
-	}
-	h.typecnt = binary.BigEndian.Uint32(data[:4])
-	data = data[4:]
-	// charcnt
-	if len(data) < 4 {
-		return data, h, fmt.Errorf("missing charcnt")
-	}
-	h.charcnt = binary.BigEndian.Uint32(data[:4])
-	data = data[4:]
-	return data, h, nil
-}
-
-func printDataBlock(data []byte, h header, timeFn func([]byte) ([]byte, int64, error)) ([]byte, error) {
-	fmt.Println("Transition times:")
-	for i := uint32(0); i <  h.timecnt; i++ {
-		var ts int64
-		var err error
-		data, ts, err = timeFn(data)
-		if err != nil {
-			return data, err
-		}
-		fmt.Printf(" %d (%s UTC)\n", ts, time.Unix(ts, 0).UTC().Format("2006-01-02T15:04:05"))
-	}
-	fmt.Println("Transition types:")
-	for i := uint32(0); i <  h.timecnt; i++ {
-		if len(data) < 1 {
-			return data, fmt.Errorf("missing transition type")
-		}
-		tt := data[0]
-		if uint32(tt) > h.typecnt {
-			return data, fmt.Errorf("transition type out of range")
-		}
-		data = data[1:]
-		fmt.Printf(" %d\n", tt)
-	}
-	fmt.Println("Local time type records:")
-	for i := uint32(0); i < h.typecnt; i++ {
-		if len(data) < 6 {
-			return data, fmt.Errorf("missing type record")
-		}
-		utoff := int32(binary.BigEndian.Uint32(data[0:4]))
-		dst := data[4]
-		idx := data[5]
-		if uint32(idx) > h.charcnt-1 {
-			return data, fmt.Errorf("idx %d out of range (0..%d)", idx, h.charcnt-1)
-		}
-		data = data[6:]
-		fmt.Printf(" (%d) utoff=%d dst=%d idx=%d\n", i, utoff, dst, idx)
-	}
-	fmt.Println("Time zone designations:")
-	if uint32(len(data)) < h.charcnt {
-		return data, fmt.Errorf("missing time zone designations")
-	}
-	tzDesig := data[:h.charcnt]
-	data = data[h.charcnt:]
-	err := printTzDesig(tzDesig)
-	if err != nil {
-		return data, err
-	}
-	fmt.Println("Leap second records:")
-	for i := uint32(0); i < h.leapcnt; i++ {
-		var occur int64
-		data, occur, err = timeFn(data)
-		if err != nil {
-			return data, err
-		}
-		if len(data) < 4 {
-			return data, fmt.Errorf("missing corr")
-		}
-		corr := int32(binary.BigEndian.Uint32(data[0:4]))
-		data = data[4:]
-		fmt.Printf(" occur=%d corr=%d\n", occur, corr)
-	}
-	fmt.Println("Standard/wall indicators:")
-	for i := uint32(0); i < h.isstdcnt; i++ {
-		if len(data) < 1 {
-			return data, fmt.Errorf("missing std/wall indicator")
-		}
-		switch data[0] {
-		case 0:
-			fmt.Printf(" (%d) wall\n", i)
-		case 1:
-			fmt.Printf(" (%d) standard\n", i)
-		default:
-			return data, fmt.Errorf("unsupported std/wall indicator: %d", data[0])
+		return fmt.Sprintf("month=%s week=%s weekday=%s time=%s",
+			num(int64(r.Month)), num(int64(r.Week)), num(int64(r.Weekday)), num(int64(r.Time)))
+	}
+}
+
+func printHeader(h tzif.Header) {
+	printHeading("Header:")
+	fmt.Fprintln(stdout, " version:", h.Version)
+	fmt.Fprintf(stdout, " isutcnt: %s\n", num(int64(h.IsUTCnt)))
+	fmt.Fprintf(stdout, " isstdcnt: %s\n", num(int64(h.IsStdCnt)))
+	fmt.Fprintf(stdout, " leapcnt: %s\n", num(int64(h.LeapCnt)))
+	fmt.Fprintf(stdout, " timecnt: %s\n", num(int64(h.TimeCnt)))
+	fmt.Fprintf(stdout, " typecnt: %s\n", num(int64(h.TypeCnt)))
+	fmt.Fprintf(stdout, " charcnt: %s\n", num(int64(h.CharCnt)))
+}
+
+// num formats a numeric field value in the base selected by -radix.
+func num(v int64) string {
+	if *radix == 16 {
+		return fmt.Sprintf("%#x", v)
+	}
+	return strconv.FormatInt(v, 10)
+}
+
+func printDataBlock(b *tzif.DataBlock) {
+	if showSection("header") {
+		printHeader(b.Header)
+	}
+	if showSection("transitions") {
+		printHeading("Transition times:")
+		// Colorized output is for reading on a terminal, align the columns there.
+		width := 0
+		if colorStdout {
+			for _, ts := range b.TransitionTimes {
+				width = max(width, len(num(ts)))
+			}
+		}
+		total := uint32(len(b.TransitionTimes))
+		showProgress := *progressBar && total > progressThreshold && isTerminal(os.Stderr)
+		for i, ts := range b.TransitionTimes {
+			if showProgress && uint32(i)%(total/100) == 0 {
+				printProgress(uint32(i), total)
+			}
+			if !inRange(ts) {
+				continue
+			}
+			fmt.Fprintf(stdout, " %*s%s\n", width, num(ts), describeTransitionTime(b, i))
+		}
+		if showProgress {
+			printProgress(total, total)
+			fmt.Fprintln(os.Stderr)
 		}
-		data = data[1:]
+		printSectionError(b, tzif.SectionTransitionTimes)
+		if err := b.CheckTransitionOrder(); err != nil {
+			warnf("%v", err)
+		}
+		printHeading("Transition types:")
+		for i, tt := range b.TransitionTypes {
+			if i < len(b.TransitionTimes) && !inRange(b.TransitionTimes[i]) {
+				continue
+			}
+			fmt.Fprintf(stdout, " %s\n", num(int64(tt)))
+		}
+		printSectionError(b, tzif.SectionTransitionTypes)
 	}
-	fmt.Println("UT/local indicators:")
-	for i := uint32(0); i < h.isutcnt; i++ {
-		if len(data) < 1 {
-			return data, fmt.Errorf("missing ut/local indicator")
+	if showSection("types") {
+		printHeading("Local time type records:")
+		indexWidth, utoffWidth := 0, 0
+		if colorStdout {
+			indexWidth = len(fmt.Sprint(len(b.LocalTimeTypes) - 1))
+			for _, t := range b.LocalTimeTypes {
+				utoffWidth = max(utoffWidth, len(num(int64(t.UTOff))))
+			}
 		}
-		switch data[0] {
-		case 0:
-			fmt.Printf(" (%d) local\n", i)
-		case 1:
-			fmt.Printf(" (%d) UT\n", i)
-		default:
-			return data, fmt.Errorf("unsupported UT/local indicator: %d", data[0])
+		for i, t := range b.LocalTimeTypes {
+			line := fmt.Sprintf(" (%*d) utoff=%-*s dst=%d idx=%s", indexWidth, i, utoffWidth, num(int64(t.UTOff)), t.DST, num(int64(t.Idx)))
+			if !*noResolve {
+				line += " desig=" + resolveDesignation(b, t.Idx)
+				if desig, err := designation(b, t.Idx); err == nil {
+					line += " = " + zoneLabel(desig, t.UTOff)
+				}
+			}
+			if t.DST != 0 {
+				line = paint(colorStdout, ansiCyan, line)
+			}
+			fmt.Fprintln(stdout, line)
+		}
+		printSectionError(b, tzif.SectionLocalTimeTypes)
+		warnSubMinuteOffsets(b)
+		printNegativeDST(b)
+	}
+	if showSection("designations") {
+		printHeading("Time zone designations:")
+		printTzDesig(b.Designations)
+		printSectionError(b, tzif.SectionDesignations)
+	}
+	if showSection("leap") {
+		printHeading("Leap second records:")
+		_, prevCorr := leapTable(b)
+		for _, ls := range b.LeapSeconds {
+			if inRange(ls.Occur) {
+				fmt.Fprintf(stdout, " occur=%s corr=%s (%s)\n", num(ls.Occur), num(int64(ls.Corr)), describeLeapSecond(ls, prevCorr))
+			}
+			prevCorr = ls.Corr
+		}
+		if err := b.CheckLeapSeconds(); err != nil && b.Errors[tzif.SectionLeapSeconds] == nil {
+			warnf("%v", err)
+		}
+		if records, prevCorr := leapTable(b); len(records) > 0 && b.Errors[tzif.SectionLeapSeconds] == nil {
+			printLeapBase(records, prevCorr)
+		}
+		printSectionError(b, tzif.SectionLeapSeconds)
+	}
+	if showSection("indicators") {
+		printHeading("Standard/wall indicators:")
+		for i, isStd := range b.IsStd {
+			if isStd {
+				fmt.Fprintf(stdout, " (%d) standard\n", i)
+			} else {
+				fmt.Fprintf(stdout, " (%d) wall\n", i)
+			}
+		}
+		printSectionError(b, tzif.SectionIsStd)
+		printHeading("UT/local indicators:")
+		for i, isUT := range b.IsUT {
+			if isUT {
+				fmt.Fprintf(stdout, " (%d) UT\n", i)
+			} else {
+				fmt.Fprintf(stdout, " (%d) local\n", i)
+			}
+		}
+		printSectionError(b, tzif.SectionIsUT)
+	}
+}
+
+// sectionNames are the names accepted by -section, in the order the sections are printed.
+var sectionNames = []string{"header", "transitions", "types", "designations", "leap", "indicators", "footer"}
+
+// sectionSet is the set of sections selected with -section.
+type sectionSet map[string]bool
+
+func (s sectionSet) String() string {
+	var names []string
+	for _, name := range sectionNames {
+		if s[name] {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+func (s sectionSet) Set(name string) error {
+	for _, valid := range sectionNames {
+		if name == valid {
+			s[name] = true
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown section %q, valid sections are: %s", name, strings.Join(sectionNames, ", "))
+}
+
+var sections = sectionSet{}
+
+// showSection reports whether the section should be printed, all sections are printed if none was selected.
+func showSection(name string) bool {
+	return len(sections) == 0 || sections[name]
+}
+
+// parseOptions returns the parsing options set by the command line flags.
+func parseOptions() tzif.Options {
+	return tzif.Options{
+		BestEffort: *bestEffort,
+		Strict:     *strict,
+		Lenient:    *lenient,
+		MaxCount:   uint32(min(*maxCount, math.MaxUint32)),
+		MaxSize:    *maxSize,
+	}
+}
+
+// printWarnings prints the violations tolerated by -lenient to stderr.
+func printWarnings(f *tzif.File) {
+	for _, w := range f.V1.Warnings {
+		warnf("v1 data block: %v", w)
+	}
+	if f.V2 != nil {
+		for _, w := range f.V2.Warnings {
+			warnf("v2+ data block: %v", w)
+		}
+	}
+}
+
+// defaultTimeLayout is the default -time-format.
+const defaultTimeLayout = tzif.DefaultTimeLayout
+
+// timeLayout is the layout of printed transition times set by -time-format.
+var timeLayout = defaultTimeLayout
+
+// describeTransitionTime describes the time of transition i in UTC, or with -local in the local time
+// in effect before it, followed by the local time after it, for example " (2024-03-31T01:00:00 UTC) ->
+// 03:00:00 CEST (+02:00, dst)". It returns an empty string with -unix-only, and -utc-only overrides -local
+// and leaves out the time of the local time after it. With -no-resolve, only the time in UTC is described,
+// as the rest depends on the transition types.
+func describeTransitionTime(b *tzif.DataBlock, i int) string {
+	if *unixOnly {
+		return ""
+	}
+	shown := time.Unix(b.TransitionTimes[i], 0).UTC()
+	zone := "UTC"
+	if *noResolve {
+		return fmt.Sprintf(" (%s %s)", shown.Format(timeLayout), zone)
+	}
+	if *localTimes && !*utcOnly {
+		// Local time type 0 applies before the first transition.
+		typ := uint8(0)
+		if i > 0 && i-1 < len(b.TransitionTypes) {
+			typ = b.TransitionTypes[i-1]
+		}
+		if int(typ) >= len(b.LocalTimeTypes) {
+			return fmt.Sprintf(" (? ?)%s", localAfter(b, i, shown))
+		}
+		t := b.LocalTimeTypes[typ]
+		desig, err := designation(b, t.Idx)
+		if err != nil {
+			desig = "?"
+		}
+		shown, zone = shown.In(time.FixedZone(desig, int(t.UTOff))), desig
+	}
+	return fmt.Sprintf(" (%s %s)%s", shown.Format(timeLayout), zone, localAfter(b, i, shown))
+}
+
+// localAfter describes the local time in effect immediately after transition i,
+// for example " -> 03:00:00 CEST (+02:00, dst)". With the default -time-format, the date is included only
+// if it differs from the date of shown, the printed time of the transition.
+// It returns an empty string if the transition type does not resolve to a local time type record.
+func localAfter(b *tzif.DataBlock, i int, shown time.Time) string {
+	if i >= len(b.TransitionTypes) || int(b.TransitionTypes[i]) >= len(b.LocalTimeTypes) {
+		return ""
+	}
+	t := b.LocalTimeTypes[b.TransitionTypes[i]]
+	desig, err := designation(b, t.Idx)
+	if err != nil {
+		desig = "?"
+	}
+	local := time.Unix(b.TransitionTimes[i], 0).In(time.FixedZone(desig, int(t.UTOff)))
+	layout := "15:04:05"
+	if local.YearDay() != shown.YearDay() || timeLayout != defaultTimeLayout {
+		layout = timeLayout
+	}
+	kind := "std"
+	if t.DST != 0 {
+		kind = "dst"
+	}
+	s := fmt.Sprintf(" -> %s %s, %s", local.Format(layout), zoneLabel(desig, t.UTOff), kind)
+	if *utcOnly {
+		s = fmt.Sprintf(" -> %s, %s", zoneLabel(desig, t.UTOff), kind)
+	}
+	if t.DST != 0 {
+		s = paint(colorStdout, ansiCyan, s)
+	}
+	return s
+}
+
+// printResolved prints each transition of b with the local time type it switches to and the one in effect before,
+// for example "2024-03-31 01:00:00 UTC → CEST (UTC+02:00), DST [was CET (UTC+01:00)]". Transitions that do
+// not change local time are marked, so that a first transition to a copy of the initial type 0 is not
+// mistaken for a change. With -annotate-known-events, the transitions of well-known changes of the rules of
+// the zone the input called name is a file of are described, see knownEvents.
+func printResolved(b *tzif.DataBlock, name string) error {
+	describe := func(typ uint8) (string, tzif.LocalTimeType, error) {
+		if int(typ) >= len(b.LocalTimeTypes) {
+			return "", tzif.LocalTimeType{}, fmt.Errorf("local time type %d out of range", typ)
+		}
+		t := b.LocalTimeTypes[typ]
+		desig, err := designation(b, t.Idx)
+		return desig, t, err
+	}
+	// Local time type 0 is in effect before the first transition.
+	var prev uint8
+	for i, ts := range b.TransitionTimes {
+		if i >= len(b.TransitionTypes) {
+			return fmt.Errorf("missing transition type %d", i)
 		}
-		data = data[1:]
+		wasDesig, was, err := describe(prev)
+		if err != nil {
+			return err
+		}
+		desig, t, err := describe(b.TransitionTypes[i])
+		if err != nil {
+			return err
+		}
+		prev = b.TransitionTypes[i]
+		if !inRange(ts) {
+			continue
+		}
+		kind := "standard"
+		if t.DST != 0 {
+			kind = "DST"
+		}
+		note := ""
+		if repeatsLocalTime(b, i) {
+			// Not a change of local time. zic writes such transitions at the start of fat files, and for
+			// changes of the indicators only.
+			note = " (no change, repeats the initial local time type 0)"
+			if i > 0 {
+				note = fmt.Sprintf(" (no change, repeats the local time of transition %d)", i-1)
+			}
+		}
+		if *annotateEvents {
+			if event := knownEventAt(name, ts, was.UTOff); event != "" {
+				note += " — " + event
+			}
+		}
+		fmt.Fprintf(stdout, "%s UTC → %s, %s [was %s]%s\n", time.Unix(ts, 0).UTC().Format("2006-01-02 15:04:05"),
+			zoneLabel(desig, t.UTOff), kind, zoneLabel(wasDesig, was.UTOff), note)
+	}
+	return nil
+}
+
+// zoneLabel combines a designation and a UT offset into the form most people know a zone by, for example
+// "CET (UTC+01:00)". Numeric designations such as "+05" or "-0330" that spell the offset are shown as the
+// offset alone, "UTC+05:00", instead of twice.
+func zoneLabel(desig string, utoff int32) string {
+	if desig == numericDesignation(utoff) {
+		return "UTC" + formatUTOff(utoff)
 	}
-	return data, nil
+	return fmt.Sprintf("%s (UTC%s)", desig, formatUTOff(utoff))
 }
 
-func printTzDesig(data []byte) error {
+// numericDesignation returns the designation zic uses for a UT offset without a name, for example "+05",
+// "-0330" or "+051545".
+func numericDesignation(off int32) string {
+	sign := '+'
+	if off < 0 {
+		sign = '-'
+		off = -off
+	}
+	switch {
+	case off%60 != 0:
+		return fmt.Sprintf("%c%02d%02d%02d", sign, off/3600, off/60%60, off%60)
+	case off/60%60 != 0:
+		return fmt.Sprintf("%c%02d%02d", sign, off/3600, off/60%60)
+	}
+	return fmt.Sprintf("%c%02d", sign, off/3600)
+}
+
+// repeatsLocalTime reports whether transition i of b switches to the local time already in effect: its
+// local time type has the same offset, DST flag and designation as that of the previous transition, or
+// as local time type 0 for the first transition. Both types must be in range.
+func repeatsLocalTime(b *tzif.DataBlock, i int) bool {
+	var prev uint8
+	if i > 0 {
+		prev = b.TransitionTypes[i-1]
+	}
+	return describeType(b, prev) == describeType(b, b.TransitionTypes[i])
+}
+
+// formatUTOff formats a UT offset as +hh:mm, or +hh:mm:ss if it is not a whole number of minutes.
+func formatUTOff(off int32) string {
+	sign := '+'
+	if off < 0 {
+		sign = '-'
+		off = -off
+	}
+	if off%60 != 0 {
+		return fmt.Sprintf("%c%02d:%02d:%02d", sign, off/3600, off/60%60, off%60)
+	}
+	return fmt.Sprintf("%c%02d:%02d", sign, off/3600, off/60%60)
+}
+
+// resolveDesignation returns the quoted designation at idx for printing.
+// It warns if idx points into the middle of a designation; zic does that to share a suffix, e.g. "HST" in "AHST".
+func resolveDesignation(b *tzif.DataBlock, idx uint8) string {
+	desig, err := b.Designation(idx)
+	if err != nil {
+		return fmt.Sprintf("<%v>", err)
+	}
+	if idx > 0 && b.Designations[idx-1] != 0 {
+		start := bytes.LastIndexByte(b.Designations[:idx], 0) + 1
+		warnf("idx %d points into the middle of designation %q, sharing its suffix %q",
+			idx, b.Designations[start:int(idx)+len(desig)], desig)
+	}
+	return strconv.Quote(capDesignation(desig))
+}
+
+// printSectionError prints the error of a section skipped in best-effort mode, if any.
+func printSectionError(b *tzif.DataBlock, section tzif.Section) {
+	err := b.Errors[section]
+	if err == nil {
+		return
+	}
+	fmt.Fprintf(stdout, " error: %v\n", err)
+	fmt.Fprintln(stdout, " (section recovered, remaining entries skipped, output may be unreliable)")
+}
+
+// progressThreshold is the number of transitions above which -progress-bar shows progress.
+const progressThreshold = 100000
+
+// printProgress overwrites the current stderr line with the number of transitions printed so far.
+func printProgress(done, total uint32) {
+	fmt.Fprintf(os.Stderr, "\rtransitions: %3d%% (%d/%d)", uint64(done)*100/uint64(total), done, total)
+}
+
+// isTerminal reports whether f is a terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+func printTzDesig(data []byte) {
 	start := 0
 	for end := 0; end < len(data); end++ {
 		if data[end] == 0 {
-			fmt.Printf(" %q\n", data[start:end])
-			start = end+1
+			if desig := capDesignation(string(data[start:end])); len(desig) < end-start {
+				fmt.Fprintf(stdout, " %q (truncated from %d bytes)\n", desig, end-start)
+			} else {
+				fmt.Fprintf(stdout, " %q\n", desig)
+			}
+			start = end + 1
+		}
+	}
+}
+
+// warnedDesignations are the designations capDesignation warned about, so that each is reported once.
+var (
+	warnedDesignationsMu sync.Mutex
+	warnedDesignations   = make(map[string]bool)
+)
+
+// capDesignation returns desig truncated to -max-desig-len bytes, warning once per designation that is
+// longer. Every output printing designations passes them through it.
+func capDesignation(desig string) string {
+	if *maxDesigLen < 0 || len(desig) <= *maxDesigLen {
+		return desig
+	}
+	warnedDesignationsMu.Lock()
+	warned := warnedDesignations[desig]
+	warnedDesignations[desig] = true
+	warnedDesignationsMu.Unlock()
+	if !warned {
+		warnf("time zone designation %q... is %d bytes long, longer than %d", desig[:*maxDesigLen], len(desig), *maxDesigLen)
+	}
+	return desig[:*maxDesigLen]
+}
+
+// designation returns the designation at idx of b for printing, see capDesignation.
+func designation(b *tzif.DataBlock, idx uint8) (string, error) {
+	desig, err := b.Designation(idx)
+	return capDesignation(desig), err
+}
+
+// printFooterHexDump prints the footer including its enclosing newlines as a hex dump,
+// followed by notes about any framing problems found.
+func printFooterHexDump(footer []byte) {
+	printHeading("Footer hex:")
+	for _, line := range strings.SplitAfter(strings.TrimSuffix(hex.Dump(footer), "\n"), "\n") {
+		fmt.Fprintf(stdout, " %s", line)
+	}
+	fmt.Fprintln(stdout)
+	for _, problem := range footerFramingProblems(footer) {
+		fmt.Fprintf(stdout, " note: %s\n", problem)
+	}
+}
+
+// footerFramingProblems checks that the footer is a newline, a TZ string without NUL or newline bytes
+// and another newline.
+func footerFramingProblems(footer []byte) []string {
+	if len(footer) == 0 {
+		return []string{"footer is empty, expected at least two newlines"}
+	}
+	var problems []string
+	if footer[0] != '\n' {
+		problems = append(problems, fmt.Sprintf("byte 0: expected leading newline, found 0x%02x", footer[0]))
+	}
+	last := len(footer) - 1
+	if last == 0 || footer[last] != '\n' {
+		problems = append(problems, fmt.Sprintf("byte %d: expected trailing newline, found 0x%02x", last, footer[last]))
+	}
+	for i := 1; i < last; i++ {
+		switch footer[i] {
+		case 0:
+			problems = append(problems, fmt.Sprintf("byte %d: embedded NUL", i))
+		case '\n':
+			problems = append(problems, fmt.Sprintf("byte %d: newline inside TZ string", i))
 		}
 	}
-	if start != len(data) {
-		return fmt.Errorf("extra data at end of tz desig")
+	return problems
+}
+
+// printCArray prints data read from source as a C array definition named name,
+// preceded by a macro with its length.
+func printCArray(data []byte, source, name string) {
+	lenMacro := strings.ToUpper(name) + "_LEN"
+	fmt.Fprintf(stdout, "/* TZif data read from %s by tzif2text, %d bytes. */\n", source, len(data))
+	fmt.Fprintf(stdout, "#define %s %d\n", lenMacro, len(data))
+	fmt.Fprintf(stdout, "const unsigned char %s[%s] = {\n", name, lenMacro)
+	for i := 0; i < len(data); i += 12 {
+		line := data[i:min(i+12, len(data))]
+		fmt.Fprint(stdout, "\t")
+		for j, b := range line {
+			if j > 0 {
+				fmt.Fprint(stdout, " ")
+			}
+			fmt.Fprintf(stdout, "0x%02x,", b)
+		}
+		fmt.Fprintln(stdout)
+	}
+	fmt.Fprintln(stdout, "};")
+}
+
+// listTZDir prints the sorted names of all TZif files under dir, or $TZDIR if dir is empty.
+// Zones that are symbolic links are printed as aliases of their target.
+func listTZDir(dir string) error {
+	dir = zoneinfoDir(dir)
+	zones, err := walkZones(dir)
+	if err != nil {
+		return err
+	}
+	for _, zone := range zones {
+		if zone.alias != "" {
+			fmt.Fprintf(stdout, "%s -> %s\n", zone.name, zone.alias)
+			continue
+		}
+		if !*summary {
+			fmt.Fprintln(stdout, zone.name)
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, zone.name))
+		if err != nil {
+			return err
+		}
+		f, err := tzif.Parse(bytes.NewReader(data))
+		if err != nil {
+			return &inputError{name: zone.name, err: err}
+		}
+		fmt.Fprintln(stdout, summaryLine(zone.name, f, len(data)))
 	}
 	return nil
 }
+
+// summaryLine describes f, which is size bytes long, in one line starting with name, for example
+// "Europe/Prague version=2 transitions=144 first=1849-12-31 last=2037-10-25 offsets=4 dst=yes footer=yes size=2301".
+// The transitions, dates and offsets are those of the most precise data block.
+func summaryLine(name string, f *tzif.File, size int) string {
+	b := lastBlock(f)
+	first, last := "-", "-"
+	if n := len(b.TransitionTimes); n > 0 {
+		first = time.Unix(b.TransitionTimes[0], 0).UTC().Format("2006-01-02")
+		last = time.Unix(b.TransitionTimes[n-1], 0).UTC().Format("2006-01-02")
+	}
+	offsets := make(map[int32]bool)
+	dst := "no"
+	for _, t := range b.LocalTimeTypes {
+		offsets[t.UTOff] = true
+		if t.DST != 0 {
+			dst = "yes"
+		}
+	}
+	footer := "no"
+	if tz, err := tzif.ParseFooter(f.Footer); err == nil && tz != nil {
+		footer = "yes"
+	}
+	return fmt.Sprintf("%s version=%d transitions=%d first=%s last=%s offsets=%d dst=%s footer=%s size=%d",
+		name, b.Header.Version, len(b.TransitionTimes), first, last, len(offsets), dst, footer, size)
+}
+
+// zoneinfoDir returns dir, or if it is empty $TZDIR or the system zoneinfo directory.
+func zoneinfoDir(dir string) string {
+	if dir == "" {
+		dir = os.Getenv("TZDIR")
+	}
+	if dir == "" {
+		dir = "/usr/share/zoneinfo"
+	}
+	return dir
+}
+
+// zoneEntry is a TZif file found by walkZones.
+type zoneEntry struct {
+	// name is the slash-separated path relative to the walked directory.
+	name string
+	// alias is the zone the file links to, relative to the walked directory if it is inside it,
+	// or empty if the file is not a symbolic link.
+	alias string
+}
+
+// walkZones returns the TZif files under dir sorted by name, skipping other files such as zone.tab.
+func walkZones(dir string) ([]zoneEntry, error) {
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return nil, err
+	}
+	var zones []zoneEntry
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		ok, err := hasMagic(path)
+		if err != nil || !ok {
+			return err
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		zone := zoneEntry{name: filepath.ToSlash(name)}
+		if d.Type()&fs.ModeSymlink != 0 {
+			target, err := filepath.EvalSymlinks(path)
+			if err != nil {
+				return err
+			}
+			zone.alias = target
+			if rel, err := filepath.Rel(root, target); err == nil && rel != ".." && !strings.HasPrefix(rel, "../") {
+				zone.alias = filepath.ToSlash(rel)
+			}
+		}
+		zones = append(zones, zone)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(zones, func(i, j int) bool { return zones[i].name < zones[j].name })
+	return zones, nil
+}
+
+// hasMagic reports whether the file starts with the TZif magic.
+// Symbolic links to directories are not followed and report false.
+func hasMagic(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil || fi.IsDir() {
+		return false, err
+	}
+	buf := make([]byte, len(tzif.Magic))
+	_, err = io.ReadFull(f, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return string(buf) == tzif.Magic, nil
+}
+
+// leapBaseOffsets maps each -leap-base to the number of seconds its occurrence times are ahead of UTC
+// in addition to the leap seconds inserted before them. RFC 8536 uses utc, tai counts the 10 s
+// TAI-UTC difference in effect when leap seconds were introduced in 1972.
+var leapBaseOffsets = map[string]int64{"utc": 0, "tai": 10}
+
+// knownLeapSeconds contains the POSIX times just after each leap second announced by the IERS,
+// as listed in leap-seconds.list distributed with tzdata.
+var knownLeapSeconds = map[int64]bool{
+	78796800:   true, // 1 Jul 1972
+	94694400:   true, // 1 Jan 1973
+	126230400:  true, // 1 Jan 1974
+	157766400:  true, // 1 Jan 1975
+	189302400:  true, // 1 Jan 1976
+	220924800:  true, // 1 Jan 1977
+	252460800:  true, // 1 Jan 1978
+	283996800:  true, // 1 Jan 1979
+	315532800:  true, // 1 Jan 1980
+	362793600:  true, // 1 Jul 1981
+	394329600:  true, // 1 Jul 1982
+	425865600:  true, // 1 Jul 1983
+	489024000:  true, // 1 Jul 1985
+	567993600:  true, // 1 Jan 1988
+	631152000:  true, // 1 Jan 1990
+	662688000:  true, // 1 Jan 1991
+	709948800:  true, // 1 Jul 1992
+	741484800:  true, // 1 Jul 1993
+	773020800:  true, // 1 Jul 1994
+	820454400:  true, // 1 Jan 1996
+	867715200:  true, // 1 Jul 1997
+	915148800:  true, // 1 Jan 1999
+	1136073600: true, // 1 Jan 2006
+	1230768000: true, // 1 Jan 2009
+	1341100800: true, // 1 Jul 2012
+	1435708800: true, // 1 Jul 2015
+	1483228800: true, // 1 Jan 2017
+}
+
+// describeLeapSecond describes the UTC time of the leap second and the cumulative correction,
+// prevCorr is the correction of the previous record. The occurrence is interpreted in -leap-base.
+func describeLeapSecond(ls tzif.LeapSecond, prevCorr int32) string {
+	// Midnight UTC following the leap second.
+	midnight := time.Unix(ls.Occur-int64(prevCorr)-leapBaseOffsets[*leapBase], 0).UTC()
+	day := midnight.Add(-time.Second).Format("2006-01-02")
+	var at string
+	switch ls.Corr - prevCorr {
+	case 1:
+		at = fmt.Sprintf("inserted %sT23:59:60 UTC", day)
+	case -1:
+		at = fmt.Sprintf("deleted %sT23:59:59 UTC", day)
+	case 0:
+		at = fmt.Sprintf("table expires at %s UTC", midnight.Format("2006-01-02T15:04:05"))
+	default:
+		at = fmt.Sprintf("change %+d before %s UTC", ls.Corr-prevCorr, midnight.Format("2006-01-02T15:04:05"))
+	}
+	return fmt.Sprintf("%s, cumulative %+d", at, ls.Corr)
+}
+
+// leapTable returns the leap second records of b that describe leap seconds, without the expiration
+// record of version 4 files, and the correction in effect before the first of them.
+func leapTable(b *tzif.DataBlock) ([]tzif.LeapSecond, int32) {
+	records := b.LeapSeconds
+	if _, ok := b.LeapSecondsExpire(); ok {
+		records = records[:len(records)-1]
+	}
+	if !b.LeapSecondsTruncated() {
+		return records, 0
+	}
+	// The table is truncated at the start, the first record is a leap second like the ones that follow.
+	first := b.LeapSeconds[0].Corr
+	if first > 0 {
+		return records, first - 1
+	}
+	return records, first + 1
+}
+
+// printLeapBase prints which -leap-base makes the leap second occurrences match known leap seconds,
+// prevCorr is the correction before the first record.
+func printLeapBase(leapSeconds []tzif.LeapSecond, prevCorr int32) {
+	if leapBaseMatches(leapSeconds, prevCorr, *leapBase) {
+		fmt.Fprintf(stdout, " base=%s (occurrences match known leap seconds)\n", *leapBase)
+		return
+	}
+	for _, base := range []string{"utc", "tai"} {
+		if leapBaseMatches(leapSeconds, prevCorr, base) {
+			fmt.Fprintf(stdout, " base=%s does not match known leap seconds, detected base=%s\n", *leapBase, base)
+			return
+		}
+	}
+	fmt.Fprintf(stdout, " base=%s does not match known leap seconds, no base does\n", *leapBase)
+}
+
+// leapBaseMatches reports whether all occurrences fall on known leap seconds when interpreted in base.
+func leapBaseMatches(leapSeconds []tzif.LeapSecond, prevCorr int32, base string) bool {
+	for _, ls := range leapSeconds {
+		if !knownLeapSeconds[ls.Occur-int64(prevCorr)-leapBaseOffsets[base]] {
+			return false
+		}
+		prevCorr = ls.Corr
+	}
+	return true
+}
+
+// warnStale warns about zones that observe DST, whose transitions end more than a year before
+// the current year and that have no footer rule to continue them.
+// Such files may be truncated or the zone may have abolished DST without that being recorded.
+func warnStale(b *tzif.DataBlock, footer []byte) {
+	if len(b.TransitionTimes) == 0 || len(bytes.TrimSpace(footer)) > 0 {
+		return
+	}
+	observesDST := false
+	for _, t := range b.LocalTimeTypes {
+		observesDST = observesDST || t.DST != 0
+	}
+	lastYear := time.Unix(b.TransitionTimes[len(b.TransitionTimes)-1], 0).UTC().Year()
+	if observesDST && lastYear < time.Now().UTC().Year()-1 {
+		warnf("zone observes DST but its last transition is in %d and there is no footer rule, the data may be stale", lastYear)
+	}
+}
+
+// printNegativeDST annotates DST types whose offset is lower than that of the standard time types
+// before and after them. This is how zones like Europe/Dublin model standard time in summer and DST in winter.
+func printNegativeDST(b *tzif.DataBlock) {
+	for _, n := range negativeDSTTypes(b) {
+		fmt.Fprintf(stdout, " (%d) negative DST (winter time): utoff=%s is below standard utoff=%s\n",
+			n.typ, num(int64(b.LocalTimeTypes[n.typ].UTOff)), num(int64(n.stdUTOff)))
+	}
+}
+
+// negativeDST is a DST local time type with an offset lower than the standard time around it.
+type negativeDST struct {
+	typ uint8
+	// transition is the index of the first transition to typ between standard time types with higher offsets.
+	transition int
+	// stdUTOff is the offset of the standard time type following that transition.
+	stdUTOff int32
+}
+
+// negativeDSTTypes returns the DST types of b whose offset is lower than that of the standard time
+// types before and after them, in the order of the first transition to each.
+func negativeDSTTypes(b *tzif.DataBlock) []negativeDST {
+	types := b.LocalTimeTypes
+	var found []negativeDST
+	reported := make(map[uint8]bool)
+	for i := 1; i+1 < len(b.TransitionTypes); i++ {
+		prev, idx, next := b.TransitionTypes[i-1], b.TransitionTypes[i], b.TransitionTypes[i+1]
+		if int(prev) >= len(types) || int(idx) >= len(types) || int(next) >= len(types) {
+			continue
+		}
+		if types[idx].DST == 0 || types[prev].DST != 0 || types[next].DST != 0 || reported[idx] {
+			continue
+		}
+		if types[idx].UTOff < types[prev].UTOff && types[idx].UTOff < types[next].UTOff {
+			reported[idx] = true
+			found = append(found, negativeDST{typ: idx, transition: i, stdUTOff: types[next].UTOff})
+		}
+	}
+	return found
+}
+
+// wholeMinuteOffsetsSince is the time after which all zones use offsets that are whole minutes (1972-01-01).
+const wholeMinuteOffsetsSince = 63072000
+
+// warnSubMinuteOffsets warns about modern transitions to offsets that are not whole minutes,
+// which are almost certainly data errors.
+func warnSubMinuteOffsets(b *tzif.DataBlock) {
+	for i, ts := range b.TransitionTimes {
+		if ts < wholeMinuteOffsetsSince || i >= len(b.TransitionTypes) || int(b.TransitionTypes[i]) >= len(b.LocalTimeTypes) {
+			continue
+		}
+		if utoff := b.LocalTimeTypes[b.TransitionTypes[i]].UTOff; utoff%60 != 0 {
+			warnf("transition %d at %s UTC switches to offset %d, which is not a whole minute",
+				i, time.Unix(ts, 0).UTC().Format("2006-01-02T15:04:05"), utoff)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+func TestZoneLabel(t *testing.T) {
+	for _, tt := range []struct {
+		desig string
+		utoff int32
+		want  string
+	}{
+		{"CET", 3600, "CET (UTC+01:00)"},
+		{"LMT", 3464, "LMT (UTC+00:57:44)"},
+		{"+05", 5 * 3600, "UTC+05:00"},
+		{"-03", -3 * 3600, "UTC-03:00"},
+		{"+0530", 5*3600 + 30*60, "UTC+05:30"},
+		{"-0330", -(3*3600 + 30*60), "UTC-03:30"},
+		// A numeric designation that does not spell the offset is shown with it.
+		{"+05", 4 * 3600, "+05 (UTC+04:00)"},
+		{"-00", 0, "-00 (UTC+00:00)"},
+	} {
+		if got := zoneLabel(tt.desig, tt.utoff); got != tt.want {
+			t.Errorf("zoneLabel(%q, %d) = %q, want %q", tt.desig, tt.utoff, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/martin-sucha/tzif2text/tzif"
+)
+
+// normalizeMain writes the file at path, or stdin if path is empty, in the canonical form of
+// normalizeFile to stdout.
+func normalizeMain(path string) error {
+	var f *tzif.File
+	var err error
+	if path == "" {
+		f, err = parseInput(os.Stdin)
+	} else {
+		f, err = parsePath(path)
+	}
+	if err != nil {
+		return err
+	}
+	out, err := normalizeFile(f)
+	if err != nil {
+		return err
+	}
+	_, err = out.WriteTo(os.Stdout)
+	return err
+}
+
+// normalizeFile returns f in a canonical form, so that files built by different toolchains from the
+// same data encode to the same bytes. The transitions of the most precise data block are sorted by time,
+// of several transitions at the same time only the last one in the transition table is kept. Identical
+// local time types are merged, those and the designations no transition uses are dropped, and the rest
+// are numbered in the order of first use. The standard/wall and UT/local indicators are dropped unless
+// f is a version 1 file, in which types differing in them are not merged. The footer TZ string is
+// written in the normalized form of TZString.String. Slim files stay slim, the v1 data block of fat
+// files is derived from the v2+ data block, replacing whatever it held.
+func normalizeFile(f *tzif.File) (*tzif.File, error) {
+	b := lastBlock(f)
+	if len(b.TransitionTypes) < len(b.TransitionTimes) {
+		return nil, fmt.Errorf("missing transition types")
+	}
+	order := make([]int, len(b.TransitionTimes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return b.TransitionTimes[order[i]] < b.TransitionTimes[order[j]] })
+	sorted := &tzif.DataBlock{
+		Header:         b.Header,
+		LocalTimeTypes: b.LocalTimeTypes,
+		Designations:   b.Designations,
+		LeapSeconds:    b.LeapSeconds,
+		IsStd:          b.IsStd,
+		IsUT:           b.IsUT,
+	}
+	for _, i := range order {
+		if n := len(sorted.TransitionTimes); n > 0 && sorted.TransitionTimes[n-1] == b.TransitionTimes[i] {
+			sorted.TransitionTypes[n-1] = b.TransitionTypes[i]
+			continue
+		}
+		sorted.TransitionTimes = append(sorted.TransitionTimes, b.TransitionTimes[i])
+		sorted.TransitionTypes = append(sorted.TransitionTypes, b.TransitionTypes[i])
+	}
+	v, err := compactTypes(sorted, len(sorted.TransitionTimes), f.V2 == nil)
+	if err != nil {
+		return nil, err
+	}
+	var footer []byte
+	if f.V2 != nil {
+		tz, err := parseFooter(f)
+		if err != nil {
+			return nil, err
+		}
+		footer = []byte("\n\n")
+		if tz != nil {
+			footer = []byte("\n" + tz.String() + "\n")
+		}
+	}
+	return replaceBlock(f, v, footer)
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestNormalizeFileEqual(t *testing.T) {
+	for _, name := range []string{"prague-fat.tzif", "prague-slim.tzif", "right-utc.tzif", "sydney-slim.tzif",
+		"tokyo-slim.tzif", "utc.tzif"} {
+		f := readTestFile(t, name)
+		out, err := normalizeFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !out.Equal(f) || !f.Equal(out) {
+			t.Errorf("%s: normalized file is not equal to the original", name)
+		}
+		h1, err := semanticHash(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h2, err := semanticHash(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(h1) != string(h2) {
+			t.Errorf("%s: normalized file hashes differently", name)
+		}
+		if differ, err := diffFiles(f, out); err != nil || differ {
+			t.Errorf("%s: diff with the normalized file: %t, %v", name, differ, err)
+		}
+	}
+}
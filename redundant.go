@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/martin-sucha/tzif2text/tzif"
+)
+
+// printRedundant prints the transitions at the end of the v2+ data block that the footer TZ string
+// generates, which a slim file omits, and the size of f re-encoded without them, without the local time
+// types only they use and with a placeholder v1 data block. size is the length of the original file.
+func printRedundant(f *tzif.File, size int) error {
+	if f.V2 == nil {
+		return fmt.Errorf("version 1 files have no footer")
+	}
+	tz, err := parseFooter(f)
+	if err != nil {
+		return err
+	}
+	if tz == nil {
+		return fmt.Errorf("footer has no TZ string")
+	}
+	b := f.V2
+	keep, err := b.RedundantFrom(tz)
+	if err != nil {
+		return err
+	}
+	n := len(b.TransitionTimes)
+	fmt.Fprintf(stdout, "Footer TZ string: %s\n", tz)
+	fmt.Fprintf(stdout, "Redundant transitions: %d of %d\n", n-keep, n)
+	for i := keep; i < n; i++ {
+		ts := b.TransitionTimes[i]
+		fmt.Fprintf(stdout, " (%d) %s (%s UTC)\n", i, num(ts), time.Unix(ts, 0).UTC().Format(timeLayout))
+	}
+
+	v2, err := compactTypes(b, keep, false)
+	if err != nil {
+		return err
+	}
+	slim := tzif.File{
+		// The placeholder zic -b slim writes.
+		V1: tzif.DataBlock{
+			Header:         tzif.Header{Version: b.Header.Version},
+			LocalTimeTypes: []tzif.LocalTimeType{{}},
+			Designations:   []byte{0},
+		},
+		V2:     v2,
+		Footer: f.Footer,
+	}
+	data, err := tzif.Encode(&slim)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Slim re-encoding: %d bytes instead of %d, %d bytes saved\n", len(data), size, size-len(data))
+	return nil
+}
+
+// compactTypes returns a copy of b with only the first keep transitions and the distinct local time types
+// and designations they use, besides local time type 0 which applies before the first transition.
+// If indicators is false, the standard/wall and UT/local indicators are dropped as by zic -b slim, they
+// have no effect on files with a footer TZ string. Callers pass true for version 1 files, whose readers
+// may need the indicators to apply a POSIX TZ string to times after the last transition, so types that
+// differ only in their indicators are kept apart.
+func compactTypes(b *tzif.DataBlock, keep int, indicators bool) (*tzif.DataBlock, error) {
+	out := &tzif.DataBlock{
+		Header:          b.Header,
+		TransitionTimes: b.TransitionTimes[:keep],
+		LeapSeconds:     b.LeapSeconds,
+	}
+	// Types are merged by content, as zic writes duplicates that differ only in their indicators.
+	newIndex := make(map[string]uint8)
+	add := func(typ uint8) (uint8, error) {
+		if int(typ) >= len(b.LocalTimeTypes) {
+			return 0, fmt.Errorf("local time type %d out of range", typ)
+		}
+		key := describeType(b, typ)
+		isStd := int(typ) < len(b.IsStd) && b.IsStd[typ]
+		isUT := int(typ) < len(b.IsUT) && b.IsUT[typ]
+		if indicators {
+			key += fmt.Sprintf(" isstd=%t isut=%t", isStd, isUT)
+		}
+		if idx, ok := newIndex[key]; ok {
+			return idx, nil
+		}
+		t := b.LocalTimeTypes[typ]
+		desig, err := b.Designation(t.Idx)
+		if err != nil {
+			return 0, err
+		}
+		t.Idx, err = designationIndex(out, desig)
+		if err != nil {
+			return 0, err
+		}
+		idx := uint8(len(out.LocalTimeTypes))
+		newIndex[key] = idx
+		out.LocalTimeTypes = append(out.LocalTimeTypes, t)
+		if indicators && len(b.IsStd) > 0 {
+			out.IsStd = append(out.IsStd, isStd)
+		}
+		if indicators && len(b.IsUT) > 0 {
+			out.IsUT = append(out.IsUT, isUT)
+		}
+		return idx, nil
+	}
+	if len(b.LocalTimeTypes) > 0 {
+		if _, err := add(0); err != nil {
+			return nil, err
+		}
+	}
+	for _, typ := range b.TransitionTypes[:keep] {
+		idx, err := add(typ)
+		if err != nil {
+			return nil, err
+		}
+		out.TransitionTypes = append(out.TransitionTypes, idx)
+	}
+	return out, nil
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/martin-sucha/tzif2text/tzif"
+)
+
+// compareReleases prints the zones that differ between the tarballs at oldPath and newPath: "+ NAME" for
+// zones only in the new one, "- NAME" for zones only in the old one and "~ NAME" for zones whose files are
+// not Equal, that is which differ in local time or leap seconds, not just in their encoding. It returns
+// errDiffer if there are any. See readTarZones for the tarballs.
+func compareReleases(oldPath, newPath string) error {
+	old, err := readTarZones(oldPath)
+	if err != nil {
+		return err
+	}
+	cur, err := readTarZones(newPath)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for name := range old {
+		names = append(names, name)
+	}
+	for name := range cur {
+		if _, ok := old[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	added, removed, changed := 0, 0, 0
+	for _, name := range names {
+		a, aok := old[name]
+		b, bok := cur[name]
+		switch {
+		case !aok:
+			fmt.Fprintf(stdout, "+ %s\n", name)
+			added++
+		case !bok:
+			fmt.Fprintf(stdout, "- %s\n", name)
+			removed++
+		case !a.Equal(b):
+			fmt.Fprintf(stdout, "~ %s\n", name)
+			changed++
+		}
+	}
+	fmt.Fprintf(stdout, "%d changed, %d added, %d removed, %d unchanged\n",
+		changed, added, removed, len(names)-changed-added-removed)
+	if changed+added+removed > 0 {
+		return errDiffer
+	}
+	return nil
+}
+
+// readTarZones reads the TZif files in the tarball at path, gzip compressed or not, by zone name. The tarball
+// must hold compiled zones, such as an archive of a zoneinfo directory; tzdata source releases have to be
+// compiled with zic first. Members that do not start with the TZif magic, such as tzdata.zi, are skipped.
+// Hard and symbolic links are zones too, with the file they link to. A leading directory that all zones
+// share, such as zoneinfo/, is not part of the names, so that tarballs with different ones can be compared.
+func readTarZones(path string) (map[string]*tzif.File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, err := gunzip(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	files := make(map[string]*tzif.File)
+	links := make(map[string]string)
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		name := cleanMemberName(h.Name)
+		switch h.Typeflag {
+		case tar.TypeLink:
+			links[name] = cleanMemberName(h.Linkname)
+			continue
+		case tar.TypeSymlink:
+			links[name] = cleanMemberName(pathJoinLink(name, h.Linkname))
+			continue
+		case tar.TypeReg:
+		default:
+			continue
+		}
+		var limited io.Reader = tr
+		if *maxSize > 0 {
+			limited = io.LimitReader(tr, *maxSize+1)
+		}
+		data, err := io.ReadAll(limited)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %v", path, name, err)
+		}
+		if !bytes.HasPrefix(data, []byte(tzif.Magic)) {
+			continue
+		}
+		tf, err := parseOptions().Parse(bytes.NewReader(data))
+		if err != nil {
+			return nil, &inputError{name: path + ": " + name, err: err}
+		}
+		files[name] = tf
+	}
+	for name, target := range links {
+		// Links to links are followed, a cycle or a link to a member that is not a zone is skipped.
+		for i := 0; i < len(links) && files[target] == nil; i++ {
+			next, ok := links[target]
+			if !ok {
+				break
+			}
+			target = next
+		}
+		if tf := files[target]; tf != nil {
+			files[name] = tf
+		}
+	}
+	return stripCommonDir(files), nil
+}
+
+// cleanMemberName returns the tar member name without a leading ./ or /.
+func cleanMemberName(name string) string {
+	return strings.TrimLeft(path.Clean("/"+name), "/")
+}
+
+// pathJoinLink returns the member a symbolic link at name with the given target points to.
+func pathJoinLink(name, target string) string {
+	if strings.HasPrefix(target, "/") {
+		return target
+	}
+	return path.Join(path.Dir(name), target)
+}
+
+// stripCommonDir removes the leading directory shared by all names of files, if there is one.
+func stripCommonDir(files map[string]*tzif.File) map[string]*tzif.File {
+	common := ""
+	for name := range files {
+		dir, _, ok := strings.Cut(name, "/")
+		if !ok || (common != "" && dir != common) {
+			return files
+		}
+		common = dir
+	}
+	stripped := make(map[string]*tzif.File, len(files))
+	for name, f := range files {
+		stripped[strings.TrimPrefix(name, common+"/")] = f
+	}
+	return stripped
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTarball writes a gzip compressed tarball of the given members to a temporary file. Members with
+// a "->" prefix are symbolic links to the rest of the value, the others are read from tzif/testdata.
+func writeTarball(t *testing.T, members map[string]string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	for name, src := range members {
+		if target, ok := strings.CutPrefix(src, "->"); ok {
+			if err := tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeSymlink, Linkname: target, Mode: 0o777}); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		data := []byte(src)
+		if src != "# not a zone" {
+			var err error
+			if data, err = os.ReadFile("tzif/testdata/" + src); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Size: int64(len(data)), Mode: 0o644}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "zones.tar.gz")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCompareReleases(t *testing.T) {
+	old := writeTarball(t, map[string]string{
+		"zoneinfo/Europe/Prague":     "prague-fat.tzif",
+		"zoneinfo/Europe/Bratislava": "->Prague",
+		"zoneinfo/Asia/Tokyo":        "tokyo-slim.tzif",
+		"zoneinfo/Etc/UTC":           "utc.tzif",
+		"zoneinfo/tzdata.zi":         "# not a zone",
+	})
+	cur := writeTarball(t, map[string]string{
+		// Slim instead of fat, which is the same zone.
+		"./Europe/Prague":     "prague-slim.tzif",
+		"./Europe/Bratislava": "->../Asia/Tokyo",
+		"./Asia/Tokyo":        "tokyo-slim.tzif",
+		"./Australia/Sydney":  "sydney-slim.tzif",
+	})
+	var buf bytes.Buffer
+	stdout = &buf
+	defer func() { stdout = os.Stdout }()
+	if err := compareReleases(old, cur); err != errDiffer {
+		t.Errorf("got %v, want errDiffer", err)
+	}
+	want := "+ Australia/Sydney\n- Etc/UTC\n~ Europe/Bratislava\n1 changed, 1 added, 1 removed, 2 unchanged\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
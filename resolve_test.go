@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPrintResolvedRepeatedLocalTime(t *testing.T) {
+	var buf bytes.Buffer
+	stdout = &buf
+	defer func() { stdout = os.Stdout }()
+
+	// Assembled from a description: the first transition switches to type 1, a copy of type 0 that
+	// differs only in its standard/wall indicator, and the last one repeats the local time before it.
+	f := readTestFile(t, "repeat-initial.tzif")
+	if err := printResolved(lastBlock(f), "repeat-initial.tzif"); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	want := []string{"(no change, repeats the initial local time type 0)", "", "", "(no change, repeats the local time of transition 2)"}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d:\n%s", len(lines), len(want), buf.Bytes())
+	}
+	for i, line := range lines {
+		if got := strings.HasSuffix(line, "]"); got != (want[i] == "") || !strings.HasSuffix(line, want[i]) {
+			t.Errorf("transition %d: %q, want note %q", i, line, want[i])
+		}
+	}
+}
+
+func TestPrintResolvedKnownEvents(t *testing.T) {
+	var buf bytes.Buffer
+	stdout = &buf
+	*annotateEvents = true
+	defer func() {
+		stdout = os.Stdout
+		*annotateEvents = false
+	}()
+
+	f := readTestFile(t, "sydney-slim.tzif")
+	if err := printResolved(lastBlock(f), "/usr/share/zoneinfo/Australia/Sydney"); err != nil {
+		t.Fatal(err)
+	}
+	var annotated []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if _, event, ok := strings.Cut(line, " — "); ok {
+			annotated = append(annotated, line[:10]+" "+event[:strings.Index(event, ":")])
+		}
+	}
+	// The slim file leaves the transition of October 2008 to the footer.
+	want := []string{"2008-04-05 south-eastern Australia harmonizes DST"}
+	if strings.Join(annotated, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", annotated, want)
+	}
+}
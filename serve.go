@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/martin-sucha/tzif2text/tzif"
+)
+
+// maxServeBody is the largest request body accepted by /parse, far more than any real TZif file.
+const maxServeBody = 1 << 20
+
+// serve serves the JSON representation of TZif files on addr until it fails:
+// POST /parse parses the request body, GET /zones/NAME the zone NAME under dir.
+func serve(addr, dir string) error {
+	zones := os.DirFS(dir)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/parse", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		f, err := parseInput(http.MaxBytesReader(w, r.Body, maxServeBody))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeServeJSON(w, f, "")
+	})
+	mux.HandleFunc("/zones/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		name := strings.TrimPrefix(r.URL.Path, "/zones/")
+		// fs.ReadFile rejects names that are not valid paths within zones, such as ../etc/passwd.
+		data, err := fs.ReadFile(zones, name)
+		if fi, statErr := fs.Stat(zones, name); statErr == nil && fi.IsDir() {
+			err = fs.ErrNotExist
+		}
+		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrInvalid) {
+			http.Error(w, fmt.Sprintf("zone %q not found", name), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		f, err := parseInput(bytes.NewReader(data))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("%s: %v", name, err), http.StatusUnprocessableEntity)
+			return
+		}
+		writeServeJSON(w, f, name)
+	})
+	fmt.Fprintf(os.Stderr, "serving %s on %s\n", dir, addr)
+	return http.ListenAndServe(addr, mux)
+}
+
+// writeServeJSON writes the JSON representation of f as the response.
+func writeServeJSON(w http.ResponseWriter, f *tzif.File, name string) {
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, f, name); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(buf.Bytes())
+}
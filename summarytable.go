@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/martin-sucha/tzif2text/tzif"
+)
+
+// summaryRow is a row of the table printed by -summary-table.
+type summaryRow struct {
+	zone                     string
+	version                  uint8
+	transitions, types, leap int
+	first, last              string
+	format                   string
+}
+
+// summaryRows are the rows of the inputs read so far, added by addSummaryRow.
+var summaryRows []summaryRow
+
+// addSummaryRow adds the row of f, read from the input called name, to the -summary-table.
+func addSummaryRow(name string, f *tzif.File) {
+	b := lastBlock(f)
+	row := summaryRow{
+		zone:        name,
+		version:     b.Header.Version,
+		transitions: len(b.TransitionTimes),
+		types:       len(b.LocalTimeTypes),
+		leap:        len(b.LeapSeconds),
+		first:       "-",
+		last:        "-",
+	}
+	if n := len(b.TransitionTimes); n > 0 {
+		row.first = fmt.Sprint(time.Unix(b.TransitionTimes[0], 0).UTC().Year())
+		row.last = fmt.Sprint(time.Unix(b.TransitionTimes[n-1], 0).UTC().Year())
+	}
+	switch {
+	case f.V2 != nil:
+		row.format = string(tzif.Classify(f.V1.Header, &f.V2.Header))
+	case f.V1.Header.Version > 1:
+		row.format = "unknown"
+	default:
+		row.format = string(tzif.Classify(f.V1.Header, nil))
+	}
+	summaryRows = append(summaryRows, row)
+}
+
+// printSummaryTable prints the rows added by addSummaryRow as a table aligned by text/tabwriter,
+// sorted by zone name.
+func printSummaryTable() {
+	sort.Slice(summaryRows, func(i, j int) bool { return summaryRows[i].zone < summaryRows[j].zone })
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ZONE\tVERSION\tTRANSITIONS\tTYPES\tLEAP\tFIRST YEAR\tLAST YEAR\tFAT/SLIM")
+	for _, r := range summaryRows {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%s\t%s\t%s\n",
+			r.zone, r.version, r.transitions, r.types, r.leap, r.first, r.last, r.format)
+	}
+	w.Flush()
+}
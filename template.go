@@ -0,0 +1,39 @@
+package main
+
+import (
+	"text/template"
+	"time"
+
+	"github.com/martin-sucha/tzif2text/tzif"
+)
+
+// outputTemplate is the template parsed from -template, nil if not set.
+var outputTemplate *template.Template
+
+// templateFuncs are the functions available to -template besides the text/template builtins.
+var templateFuncs = template.FuncMap{
+	// utc converts Unix time to time.Time in UTC.
+	"utc": func(t int64) time.Time { return time.Unix(t, 0).UTC() },
+	// utoff formats a UT offset as ±hh:mm[:ss].
+	"utoff": formatUTOff,
+}
+
+// parseTemplate parses the text of -template.
+func parseTemplate(text string) (*template.Template, error) {
+	return template.New("template").Funcs(templateFuncs).Parse(text)
+}
+
+// executeTemplate prints the output of -template for f read from the input called name.
+func executeTemplate(f *tzif.File, name string) error {
+	if name == "<stdin>" {
+		name = ""
+	}
+	v, err := tzif.NewView(f, name)
+	if err != nil {
+		return err
+	}
+	for i := range v.Types {
+		v.Types[i].Designation = capDesignation(v.Types[i].Designation)
+	}
+	return outputTemplate.Execute(stdout, v)
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/martin-sucha/tzif2text/tzif"
+)
+
+// unspecifiedDesignation designates local time that is unspecified, RFC 8536 section 2.
+const unspecifiedDesignation = "-00"
+
+// truncateMain writes the file at path, or stdin if path is empty, truncated to the range set by
+// -from and -until to stdout. See truncateFile.
+func truncateMain(path string) error {
+	if fromTime == math.MinInt64 && untilTime == math.MaxInt64 {
+		return fmt.Errorf("truncate needs -from or -until")
+	}
+	if fromTime >= untilTime {
+		return fmt.Errorf("-from must be before -until")
+	}
+	var f *tzif.File
+	var err error
+	if path == "" {
+		f, err = parseInput(os.Stdin)
+	} else {
+		f, err = parsePath(path)
+	}
+	if err != nil {
+		return err
+	}
+	out, err := truncateFile(f, fromTime, untilTime)
+	if err != nil {
+		return err
+	}
+	_, err = out.WriteTo(os.Stdout)
+	return err
+}
+
+// truncateFile returns f with only the transitions at or after lo and before hi, like zic -r.
+// The transitions the DST rules of the footer give after the last transition of the data block are
+// listed explicitly up to hi, or up to lo if hi is not set, so that they can be kept or dropped too.
+// If transitions before lo are dropped, local time before lo is unspecified, designated "-00",
+// and a transition at lo switches to the local time in effect then. If transitions at or after hi
+// are dropped, or the footer has DST rules giving such transitions, a transition at hi switches to
+// unspecified local time and the footer is empty. Otherwise the footer is preserved, as it gives the
+// same local time after the last kept transition. Local time types and designations no longer used
+// are dropped, and so are the standard/wall and UT/local indicators unless f is a version 1 file.
+// Slim files stay slim, the v1 data block of fat files is derived from the result.
+func truncateFile(f *tzif.File, lo, hi int64) (*tzif.File, error) {
+	b := lastBlock(f)
+	if len(b.TransitionTypes) < len(b.TransitionTimes) {
+		return nil, fmt.Errorf("missing transition types")
+	}
+	if f.V2 != nil {
+		if tz, err := tzif.ParseFooter(f.Footer); err == nil && tz != nil {
+			until := hi
+			if hi == math.MaxInt64 {
+				// The footer is kept, only the local time at lo is needed.
+				until = lo + 1
+			}
+			if b, err = expandFooter(b, tz, until); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(b.LocalTimeTypes) == 0 {
+		return nil, fmt.Errorf("no local time types")
+	}
+	if len(b.LocalTimeTypes) > 255 {
+		return nil, fmt.Errorf("no room for the local time type before %d", lo)
+	}
+	first := sort.Search(len(b.TransitionTimes), func(i int) bool { return b.TransitionTimes[i] >= lo })
+	last := sort.Search(len(b.TransitionTimes), func(i int) bool { return b.TransitionTimes[i] >= hi })
+
+	// Type 0 of tmp is the local time type before the first kept transition, the others are shifted by one.
+	tmp := &tzif.DataBlock{
+		Header:       b.Header,
+		Designations: append([]byte(nil), b.Designations...),
+		LeapSeconds:  b.LeapSeconds,
+	}
+	initial := b.LocalTimeTypes[0]
+	if first > 0 {
+		idx, err := designationIndex(tmp, unspecifiedDesignation)
+		if err != nil {
+			return nil, err
+		}
+		initial = tzif.LocalTimeType{Idx: idx}
+		if first == last || b.TransitionTimes[first] > lo {
+			tmp.TransitionTimes = append(tmp.TransitionTimes, lo)
+			tmp.TransitionTypes = append(tmp.TransitionTypes, b.TransitionTypes[first-1]+1)
+		}
+	}
+	tmp.LocalTimeTypes = append([]tzif.LocalTimeType{initial}, b.LocalTimeTypes...)
+	// Unspecified local time is neither standard nor UT.
+	shift := func(flags []bool) []bool {
+		if len(flags) == 0 {
+			return nil
+		}
+		return append([]bool{first == 0 && flags[0]}, flags...)
+	}
+	tmp.IsStd, tmp.IsUT = shift(b.IsStd), shift(b.IsUT)
+	for i := first; i < last; i++ {
+		tmp.TransitionTimes = append(tmp.TransitionTimes, b.TransitionTimes[i])
+		tmp.TransitionTypes = append(tmp.TransitionTypes, b.TransitionTypes[i]+1)
+	}
+	footer := f.Footer
+	if hi != math.MaxInt64 && (last < len(b.TransitionTimes) || footerHasRules(f)) {
+		if len(tmp.LocalTimeTypes) > 255 {
+			return nil, fmt.Errorf("no room for the local time type from %d", hi)
+		}
+		idx, err := designationIndex(tmp, unspecifiedDesignation)
+		if err != nil {
+			return nil, err
+		}
+		tmp.LocalTimeTypes = append(tmp.LocalTimeTypes, tzif.LocalTimeType{Idx: idx})
+		if len(tmp.IsStd) > 0 {
+			tmp.IsStd = append(tmp.IsStd, false)
+		}
+		if len(tmp.IsUT) > 0 {
+			tmp.IsUT = append(tmp.IsUT, false)
+		}
+		tmp.TransitionTimes = append(tmp.TransitionTimes, hi)
+		tmp.TransitionTypes = append(tmp.TransitionTypes, uint8(len(tmp.LocalTimeTypes)-1))
+		footer = []byte("\n\n")
+	}
+	v, err := compactTypes(tmp, len(tmp.TransitionTimes), f.V2 == nil)
+	if err != nil {
+		return nil, err
+	}
+	return replaceBlock(f, v, footer)
+}
+
+// expandFooter returns a copy of b with the transitions the DST rules of tz give after the last transition
+// of b and before until appended, adding the local time types and designations they need. b is returned
+// as is if tz has no rules.
+func expandFooter(b *tzif.DataBlock, tz *tzif.TZString, until int64) (*tzif.DataBlock, error) {
+	if _, _, ok := tz.Transitions(2000); !ok {
+		return b, nil
+	}
+	n := len(b.TransitionTimes)
+	out := *b
+	out.TransitionTimes = append([]int64(nil), b.TransitionTimes...)
+	out.TransitionTypes = append([]uint8(nil), b.TransitionTypes[:n]...)
+	out.LocalTimeTypes = append([]tzif.LocalTimeType(nil), b.LocalTimeTypes...)
+	out.Designations = append([]byte(nil), b.Designations...)
+	out.IsStd = append([]bool(nil), b.IsStd...)
+	out.IsUT = append([]bool(nil), b.IsUT...)
+	typeOf := func(z tzif.Zone) (uint8, error) {
+		for i, t := range out.LocalTimeTypes {
+			if desig, err := out.Designation(t.Idx); err == nil && desig == z.Name && t.UTOff == z.UTOff && (t.DST != 0) == z.DST {
+				return uint8(i), nil
+			}
+		}
+		if len(out.LocalTimeTypes) > 255 {
+			return 0, fmt.Errorf("no room for the local time type of %s", z.Name)
+		}
+		idx, err := designationIndex(&out, z.Name)
+		if err != nil {
+			return 0, err
+		}
+		t := tzif.LocalTimeType{UTOff: z.UTOff, Idx: idx}
+		if z.DST {
+			t.DST = 1
+		}
+		out.LocalTimeTypes = append(out.LocalTimeTypes, t)
+		if len(out.IsStd) > 0 {
+			out.IsStd = append(out.IsStd, false)
+		}
+		if len(out.IsUT) > 0 {
+			out.IsUT = append(out.IsUT, false)
+		}
+		return uint8(len(out.LocalTimeTypes) - 1), nil
+	}
+	last := int64(math.MinInt64)
+	lastYear := time.Unix(until, 0).UTC().Year()
+	year := lastYear - 1
+	if n > 0 {
+		last = b.TransitionTimes[n-1]
+		year = time.Unix(last, 0).UTC().Year()
+	}
+	for ; year <= lastYear; year++ {
+		start, end, _ := tz.Transitions(year)
+		for _, t := range []int64{min(start, end), max(start, end)} {
+			if t <= last || t >= until {
+				continue
+			}
+			typ, err := typeOf(tz.Lookup(t))
+			if err != nil {
+				return nil, err
+			}
+			out.TransitionTimes = append(out.TransitionTimes, t)
+			out.TransitionTypes = append(out.TransitionTypes, typ)
+			last = t
+		}
+	}
+	return &out, nil
+}
+
+// footerHasRules reports whether the footer of f has DST rules, so that it gives transitions after
+// the last one of the data block. An unparsable footer is assumed to have them.
+func footerHasRules(f *tzif.File) bool {
+	if f.V2 == nil {
+		return false
+	}
+	tz, err := tzif.ParseFooter(f.Footer)
+	return err != nil || (tz != nil && tz.DST != "")
+}
+
+// replaceBlock returns a file with the data block v and footer in place of the most precise data block
+// and footer of f. Slim files stay slim, the v1 data block of fat files is derived from v.
+func replaceBlock(f *tzif.File, v *tzif.DataBlock, footer []byte) (*tzif.File, error) {
+	if f.V2 == nil {
+		v.Header.Version = 1
+		return &tzif.File{V1: *v}, nil
+	}
+	out := &tzif.File{V2: v, Footer: footer}
+	if tzif.Classify(f.V1.Header, &f.V2.Header) == tzif.FormatFat {
+		v1, err := tzif.Convert(out, 1)
+		if err != nil {
+			return nil, fmt.Errorf("deriving the v1 data block: %v", err)
+		}
+		out.V1 = v1.V1
+	} else {
+		// The placeholder zic -b slim writes.
+		out.V1 = tzif.DataBlock{
+			LocalTimeTypes: []tzif.LocalTimeType{{}},
+			Designations:   []byte{0},
+		}
+	}
+	out.V1.Header.Version = v.Header.Version
+	return out, nil
+}
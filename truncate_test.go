@@ -0,0 +1,79 @@
+package main
+
+import (
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/martin-sucha/tzif2text/tzif"
+)
+
+// readTestFile parses a file of the tzif package testdata.
+func readTestFile(t *testing.T, name string) *tzif.File {
+	t.Helper()
+	data, err := os.ReadFile("tzif/testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := tzif.ParseBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestTruncateFile(t *testing.T) {
+	year := func(y int) int64 { return time.Date(y, time.January, 1, 0, 0, 0, 0, time.UTC).Unix() }
+	for _, name := range []string{"prague-fat.tzif", "prague-slim.tzif", "sydney-slim.tzif", "tokyo-slim.tzif", "utc.tzif"} {
+		for _, bounds := range [][2]int64{{year(1970), year(2101)}, {year(2030) + 200*86400, math.MaxInt64},
+			{math.MinInt64, year(1990)}, {year(1980), year(2040)}} {
+			lo, hi := bounds[0], bounds[1]
+			f := readTestFile(t, name)
+			out, err := truncateFile(f, lo, hi)
+			if err != nil {
+				t.Fatalf("%s [%d, %d): %v", name, lo, hi, err)
+			}
+			check := func(ts int64) {
+				got, err := out.Lookup(ts)
+				if err != nil {
+					t.Fatalf("%s [%d, %d) at %d: %v", name, lo, hi, ts, err)
+				}
+				if ts < lo || ts >= hi {
+					return
+				}
+				want, err := f.Lookup(ts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got.Name != want.Name || got.UTOff != want.UTOff || got.DST != want.DST {
+					t.Fatalf("%s [%d, %d) at %s: %+v, want %+v", name, lo, hi, time.Unix(ts, 0).UTC(), got, want)
+				}
+			}
+			// Each transition of either file and the second before it, and every day in between.
+			for _, g := range []*tzif.File{f, out} {
+				for _, ts := range lastBlock(g).TransitionTimes {
+					check(ts - 1)
+					check(ts)
+				}
+			}
+			for ts := max(lo, year(1960)) - 86400; ts < min(hi, year(2110))+86400; ts += 86400 {
+				check(ts)
+			}
+		}
+	}
+}
+
+func TestTruncateFileUnspecifiedAfter(t *testing.T) {
+	hi := time.Date(2101, time.January, 1, 0, 0, 0, 0, time.UTC).Unix()
+	for _, name := range []string{"prague-fat.tzif", "prague-slim.tzif"} {
+		out, err := truncateFile(readTestFile(t, name), math.MinInt64, hi)
+		if err != nil {
+			t.Fatal(err)
+		}
+		z, err := out.Lookup(hi)
+		if err != nil || z.Name != unspecifiedDesignation || string(out.Footer) != "\n\n" {
+			t.Errorf("%s: %+v, %v, footer %q, want %s and an empty footer", name, z, err, out.Footer, unspecifiedDesignation)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package tzif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// testBlock describes a data block written by testFile.bytes. The header counts are derived from
+// the lengths of the sections unless counts is set, so that malformed headers can be built too.
+type testBlock struct {
+	times   []int64
+	types   []uint8
+	records []LocalTimeType
+	// desigs is the raw designations section including the NULs.
+	desigs string
+	leaps  []LeapSecond
+	// isStd and isUT are the raw indicator bytes, so that values other than 0 and 1 can be written.
+	isStd, isUT []uint8
+	// counts, if not nil, replaces the derived header counts, in the order of the header:
+	// isutcnt, isstdcnt, leapcnt, timecnt, typecnt, charcnt.
+	counts *[6]uint32
+}
+
+// testFile describes a TZif file built byte by byte, independent of File.WriteTo, so that tests do not
+// depend on the zoneinfo files of the machine and can build deliberately invalid files.
+type testFile struct {
+	// version is the version written in both headers, 1 to 4.
+	version uint8
+	v1      testBlock
+	// v2 is the v2+ data block, it and footer are omitted if nil.
+	v2 *testBlock
+	// footer is written verbatim after the v2+ data block, including the enclosing newlines.
+	footer string
+}
+
+// bytes returns the encoded file.
+func (tf testFile) bytes() []byte {
+	buf := tf.v1.appendTo(nil, tf.version, 4)
+	if tf.v2 != nil {
+		buf = tf.v2.appendTo(buf, tf.version, 8)
+		buf = append(buf, tf.footer...)
+	}
+	return buf
+}
+
+// appendTo appends the header and the data block with timeSize-byte times to buf.
+func (b testBlock) appendTo(buf []byte, version uint8, timeSize int) []byte {
+	counts := [6]uint32{uint32(len(b.isUT)), uint32(len(b.isStd)), uint32(len(b.leaps)),
+		uint32(len(b.times)), uint32(len(b.records)), uint32(len(b.desigs))}
+	if b.counts != nil {
+		counts = *b.counts
+	}
+	buf = appendTestHeader(buf, magicVersion(version), counts)
+	appendTime := func(t int64) {
+		if timeSize == 4 {
+			buf = binary.BigEndian.AppendUint32(buf, uint32(int32(t)))
+		} else {
+			buf = binary.BigEndian.AppendUint64(buf, uint64(t))
+		}
+	}
+	for _, t := range b.times {
+		appendTime(t)
+	}
+	buf = append(buf, b.types...)
+	for _, r := range b.records {
+		buf = binary.BigEndian.AppendUint32(buf, uint32(r.UTOff))
+		buf = append(buf, r.DST, r.Idx)
+	}
+	buf = append(buf, b.desigs...)
+	for _, ls := range b.leaps {
+		appendTime(ls.Occur)
+		buf = binary.BigEndian.AppendUint32(buf, uint32(ls.Corr))
+	}
+	buf = append(buf, b.isStd...)
+	return append(buf, b.isUT...)
+}
+
+// appendTestHeader appends a header with the given version byte and counts, in the order of the header.
+func appendTestHeader(buf []byte, versionByte byte, counts [6]uint32) []byte {
+	buf = append(buf, Magic...)
+	buf = append(buf, versionByte)
+	buf = append(buf, make([]byte, 15)...)
+	for _, cnt := range counts {
+		buf = binary.BigEndian.AppendUint32(buf, cnt)
+	}
+	return buf
+}
+
+// testPlaceholder is the v1 data block zic -b slim writes.
+var testPlaceholder = testBlock{records: []LocalTimeType{{}}, desigs: "\x00"}
+
+// testZone is a small well-formed data block: standard time, then DST between two transitions.
+func testZone() testBlock {
+	return testBlock{
+		times:   []int64{-100, 100},
+		types:   []uint8{1, 0},
+		records: []LocalTimeType{{UTOff: 3600, Idx: 0}, {UTOff: 7200, DST: 1, Idx: 4}},
+		desigs:  "CET\x00CEST\x00",
+		leaps:   []LeapSecond{{Occur: 78796800, Corr: 1}},
+		isStd:   []uint8{1, 0},
+		isUT:    []uint8{1, 0},
+	}
+}
+
+// testSlim returns a slim version 2 file with data block b.
+func testSlim(b testBlock) testFile {
+	return testFile{version: 2, v1: testPlaceholder, v2: &b, footer: "\nCET-1CEST,M3.5.0,M10.5.0/3\n"}
+}
+
+func TestBuilder(t *testing.T) {
+	v2 := testZone()
+	for name, tf := range map[string]testFile{
+		"v1":   {version: 1, v1: testZone()},
+		"fat":  {version: 3, v1: testZone(), v2: &v2, footer: "\n<+01>-1\n"},
+		"slim": testSlim(testZone()),
+	} {
+		t.Run(name, func(t *testing.T) {
+			f, err := Options{Strict: true}.Parse(bytes.NewReader(tf.bytes()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			b := &f.V1
+			if tf.v2 != nil {
+				b = f.V2
+				if string(f.Footer) != tf.footer {
+					t.Errorf("footer %q, want %q", f.Footer, tf.footer)
+				}
+			}
+			if b.Header.Version != tf.version {
+				t.Errorf("version %d, want %d", b.Header.Version, tf.version)
+			}
+			if len(b.TransitionTimes) != 2 || b.TransitionTimes[0] != -100 || b.TransitionTimes[1] != 100 {
+				t.Errorf("transition times %v", b.TransitionTimes)
+			}
+			if desig, err := b.Designation(4); err != nil || desig != "CEST" {
+				t.Errorf("designation at 4: %q, %v", desig, err)
+			}
+			if len(b.LeapSeconds) != 1 || len(b.IsStd) != 2 || len(b.IsUT) != 2 {
+				t.Errorf("leap seconds %v, isstd %v, isut %v", b.LeapSeconds, b.IsStd, b.IsUT)
+			}
+		})
+	}
+}
@@ -0,0 +1,334 @@
+package tzif
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Convert returns a copy of f rewritten as the given version.
+//
+// Converting to version 1 keeps the transitions and leap seconds representable as 32-bit times,
+// taken from the v2+ data block if present. The local time in effect before the first kept
+// transition is preserved by a transition at the earliest 32-bit time. The DST rules of the footer
+// are expanded into transitions up to the latest 32-bit time, as in the v1 data block of fat files.
+//
+// Converting a version 1 file to version 2 or 3 copies its data block to the v2+ data block and
+// synthesizes a footer. The DST rules are derived from the last transitions if they run until 2037,
+// as zic writes them for zones observing DST, otherwise the local time type of the last transition is
+// kept; the footer is empty, leaving local time unspecified, if neither describes it. Converting
+// between versions 2, 3 and 4 only changes the version, but fails if the footer uses the version 3
+// extensions in version 2 or the leap second table uses the version 4 extensions in an earlier version.
+func Convert(f *File, version uint8) (*File, error) {
+	switch version {
+	case 1:
+		src := f.V1
+		if f.V2 != nil {
+			src = *f.V2
+		}
+		if src.LeapSecondsTruncated() {
+			return nil, fmt.Errorf("leap second table uses version 4 extensions")
+		}
+		if _, ok := src.LeapSecondsExpire(); ok {
+			// Version 1 cannot express the expiration, drop it.
+			src.LeapSeconds = src.LeapSeconds[:len(src.LeapSeconds)-1]
+		}
+		v1 := truncate32(src)
+		if f.V2 != nil {
+			tz, err := ParseFooter(f.Footer)
+			if err != nil {
+				return nil, err
+			}
+			if tz != nil {
+				expandFooter(&v1, tz)
+			}
+		}
+		v1.Header.Version = 1
+		return &File{V1: v1}, nil
+	case 2, 3, 4:
+		var out File
+		out.V1 = copyDataBlock(f.V1)
+		if f.V2 != nil {
+			v2 := copyDataBlock(*f.V2)
+			out.V2 = &v2
+			out.Footer = append([]byte(nil), f.Footer...)
+		} else {
+			v2 := copyDataBlock(f.V1)
+			out.V2 = &v2
+			footer, err := synthesizeFooter(&f.V1)
+			if err != nil {
+				return nil, err
+			}
+			out.Footer = footer
+		}
+		if version == 2 {
+			tz, err := ParseFooter(out.Footer)
+			if err != nil {
+				return nil, err
+			}
+			if tz != nil && tz.usesVersion3() {
+				return nil, fmt.Errorf("footer %q uses version 3 extensions", out.Footer)
+			}
+		}
+		if version < 4 {
+			for _, b := range []*DataBlock{&out.V1, out.V2} {
+				_, expires := b.LeapSecondsExpire()
+				if expires || b.LeapSecondsTruncated() {
+					return nil, fmt.Errorf("leap second table uses version 4 extensions")
+				}
+			}
+		}
+		out.V1.Header.Version = version
+		out.V2.Header.Version = version
+		return &out, nil
+	default:
+		return nil, fmt.Errorf("unsupported version: %d", version)
+	}
+}
+
+// copyDataBlock returns a copy of b that shares no slices with it.
+func copyDataBlock(b DataBlock) DataBlock {
+	return DataBlock{
+		Header:          b.Header,
+		TransitionTimes: append([]int64(nil), b.TransitionTimes...),
+		TransitionTypes: append([]uint8(nil), b.TransitionTypes...),
+		LocalTimeTypes:  append([]LocalTimeType(nil), b.LocalTimeTypes...),
+		Designations:    append([]byte(nil), b.Designations...),
+		LeapSeconds:     append([]LeapSecond(nil), b.LeapSeconds...),
+		IsStd:           append([]bool(nil), b.IsStd...),
+		IsUT:            append([]bool(nil), b.IsUT...),
+	}
+}
+
+// truncate32 returns a copy of b with the transitions and leap seconds outside the 32-bit range removed.
+func truncate32(b DataBlock) DataBlock {
+	out := copyDataBlock(b)
+	out.TransitionTimes = nil
+	out.TransitionTypes = nil
+	out.LeapSeconds = nil
+	for i, t := range b.TransitionTimes {
+		if t < math.MinInt32 {
+			continue
+		}
+		if t > math.MaxInt32 {
+			break
+		}
+		if len(out.TransitionTimes) == 0 && i > 0 && t > math.MinInt32 {
+			out.TransitionTimes = append(out.TransitionTimes, math.MinInt32)
+			out.TransitionTypes = append(out.TransitionTypes, b.TransitionTypes[i-1])
+		}
+		out.TransitionTimes = append(out.TransitionTimes, t)
+		out.TransitionTypes = append(out.TransitionTypes, b.TransitionTypes[i])
+	}
+	if len(out.TransitionTimes) == 0 && len(b.TransitionTimes) > 0 && b.TransitionTimes[0] < math.MinInt32 {
+		// All transitions are before the 32-bit range, the last one is in effect throughout it.
+		last := len(b.TransitionTimes) - 1
+		if b.TransitionTimes[last] < math.MinInt32 {
+			out.TransitionTimes = []int64{math.MinInt32}
+			out.TransitionTypes = []uint8{b.TransitionTypes[last]}
+		}
+	}
+	for _, ls := range b.LeapSeconds {
+		if ls.Occur >= math.MinInt32 && ls.Occur <= math.MaxInt32 {
+			out.LeapSeconds = append(out.LeapSeconds, ls)
+		}
+	}
+	return out
+}
+
+// expandFooter appends the transitions of the DST rules in tz after the last transition of b
+// up to the latest 32-bit time, adding local time types as needed.
+func expandFooter(b *DataBlock, tz *TZString) {
+	var last int64 = math.MinInt32
+	if n := len(b.TransitionTimes); n > 0 {
+		last = b.TransitionTimes[n-1]
+	}
+	year := time.Unix(last, 0).UTC().Year()
+	if _, _, ok := tz.Transitions(year); !ok {
+		return
+	}
+	std := typeIndex(b, tz.Std, tz.StdOff, false)
+	dst := typeIndex(b, tz.DST, tz.DSTOff, true)
+	for ; year <= 2038; year++ {
+		start, end, _ := tz.Transitions(year)
+		pairs := [][2]int64{{start, int64(dst)}, {end, int64(std)}}
+		if end < start {
+			pairs[0], pairs[1] = pairs[1], pairs[0]
+		}
+		for _, p := range pairs {
+			if p[0] > last && p[0] <= math.MaxInt32 {
+				b.TransitionTimes = append(b.TransitionTimes, p[0])
+				b.TransitionTypes = append(b.TransitionTypes, uint8(p[1]))
+				last = p[0]
+			}
+		}
+	}
+}
+
+// typeIndex returns the index of the local time type of b with the given designation, offset and DST flag,
+// appending it if there is none.
+func typeIndex(b *DataBlock, name string, utoff int32, dst bool) uint8 {
+	for i, t := range b.LocalTimeTypes {
+		if d, err := b.Designation(t.Idx); err == nil && d == name && t.UTOff == utoff && (t.DST != 0) == dst {
+			return uint8(i)
+		}
+	}
+	idx := bytes.Index(b.Designations, []byte(name+"\x00"))
+	if idx < 0 {
+		idx = len(b.Designations)
+		b.Designations = append(b.Designations, name+"\x00"...)
+	}
+	t := LocalTimeType{UTOff: utoff, Idx: uint8(idx)}
+	if dst {
+		t.DST = 1
+	}
+	b.LocalTimeTypes = append(b.LocalTimeTypes, t)
+	if len(b.IsStd) > 0 {
+		b.IsStd = append(b.IsStd, false)
+	}
+	if len(b.IsUT) > 0 {
+		b.IsUT = append(b.IsUT, false)
+	}
+	return uint8(len(b.LocalTimeTypes) - 1)
+}
+
+// lastRuleYear is the year in which the transitions of a version 1 file end if its zone still observes DST:
+// zic lists them up to the end of the 32-bit range, early 2038.
+const lastRuleYear = 2037
+
+// ruleWindowYears is the number of years before the last transition whose transitions must follow
+// the DST rules derived by deriveRules. Rules that pick the same day in a few years but differ in others,
+// such as the Friday before the last Sunday and the fourth Friday of a month, are told apart by a window
+// covering the years in which they differ.
+const ruleWindowYears = 8
+
+// synthesizeFooter returns a footer for the version 1 data block b, giving local time after its last
+// transition. If the transitions run until lastRuleYear, the zone observes DST until the end of the 32-bit
+// range and the DST rules are derived from the last transitions; the footer is empty, leaving local time
+// unspecified, if the preceding years do not follow such rules. If the transitions end earlier, the local
+// time type of the last transition stays in effect and is written as standard time, or the footer is empty
+// if that type is DST.
+func synthesizeFooter(b *DataBlock) ([]byte, error) {
+	n := min(len(b.TransitionTimes), len(b.TransitionTypes))
+	// zic -b fat ends the transitions with one that changes nothing at the end of the 32-bit range.
+	for n > 1 && sameZone(b, n-1, n-2) {
+		n--
+	}
+	if n > 0 && time.Unix(b.TransitionTimes[n-1], 0).UTC().Year() >= lastRuleYear {
+		trimmed := *b
+		trimmed.TransitionTimes, trimmed.TransitionTypes = b.TransitionTimes[:n], b.TransitionTypes[:n]
+		tz, ok := deriveRules(&trimmed)
+		if !ok {
+			return []byte("\n\n"), nil
+		}
+		return []byte("\n" + tz.String() + "\n"), nil
+	}
+	var typ uint8
+	if n > 0 {
+		typ = b.TransitionTypes[n-1]
+	}
+	if int(typ) >= len(b.LocalTimeTypes) {
+		return nil, fmt.Errorf("local time type %d out of range", typ)
+	}
+	lt := b.LocalTimeTypes[typ]
+	if lt.DST != 0 {
+		return []byte("\n\n"), nil
+	}
+	name, err := b.Designation(lt.Idx)
+	if err != nil {
+		return nil, err
+	}
+	tz := TZString{Std: name, StdOff: lt.UTOff}
+	return []byte("\n" + tz.String() + "\n"), nil
+}
+
+// sameZone reports whether transitions i and j of b switch to local time types with the same
+// UT offset, DST flag and designation.
+func sameZone(b *DataBlock, i, j int) bool {
+	zi, erri := b.Lookup(b.TransitionTimes[i])
+	zj, errj := b.Lookup(b.TransitionTimes[j])
+	return erri == nil && errj == nil && zi == zj
+}
+
+// deriveRules returns the TZ string with the DST rules given by the last two transitions of b, one to DST
+// and one to standard time, as month, week and weekday. ok is false if there are no such transitions or the
+// transitions of the preceding ruleWindowYears years do not follow the rules.
+func deriveRules(b *DataBlock) (tz *TZString, ok bool) {
+	n := len(b.TransitionTimes)
+	if n < 2 {
+		return nil, false
+	}
+	z1, err1 := b.Lookup(b.TransitionTimes[n-2])
+	z2, err2 := b.Lookup(b.TransitionTimes[n-1])
+	if err1 != nil || err2 != nil || z1.DST == z2.DST {
+		return nil, false
+	}
+	start, end := b.TransitionTimes[n-2], b.TransitionTimes[n-1]
+	std, dst := z2, z1
+	if z2.DST {
+		start, end = end, start
+		std, dst = z1, z2
+	}
+	// The start rule time is in standard time, the end rule time in daylight saving time.
+	for _, startRule := range rulesAt(start, std.UTOff) {
+		for _, endRule := range rulesAt(end, dst.UTOff) {
+			tz := &TZString{Std: std.Name, StdOff: std.UTOff, DST: dst.Name, DSTOff: dst.UTOff, Start: startRule, End: endRule}
+			if followsRules(b, tz) {
+				return tz, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// rulesAt returns the rules of the Mm.w.d form selecting the day of the transition at t in local time given
+// by utOff. The last week of the month is given as week 5 first, as zic writes it.
+func rulesAt(t int64, utOff int32) []*Rule {
+	local := time.Unix(t+int64(utOff), 0).UTC()
+	r := Rule{
+		Kind:    RuleMonthWeekDay,
+		Month:   int(local.Month()),
+		Week:    (local.Day()-1)/7 + 1,
+		Weekday: int(local.Weekday()),
+		Time:    int32(local.Hour()*3600 + local.Minute()*60 + local.Second()),
+	}
+	if local.Day()+7 <= daysIn(local.Month(), local.Year()) {
+		return []*Rule{&r}
+	}
+	last := r
+	last.Week = 5
+	return []*Rule{&last, &r}
+}
+
+// followsRules reports whether the transitions of b in the ruleWindowYears years before the year of its last
+// transition and in that year are those the rules of tz generate, switching to the same local time.
+func followsRules(b *DataBlock, tz *TZString) bool {
+	n := len(b.TransitionTimes)
+	last := b.TransitionTimes[n-1]
+	year := time.Unix(last, 0).UTC().Year()
+	from := time.Date(year-ruleWindowYears, time.January, 1, 0, 0, 0, 0, time.UTC).Unix()
+	var want []int64
+	for y := year - ruleWindowYears - 1; y <= year+1; y++ {
+		start, end, _ := tz.Transitions(y)
+		for _, t := range []int64{start, end} {
+			if t >= from && t <= last {
+				want = append(want, t)
+			}
+		}
+	}
+	sort.Slice(want, func(i, j int) bool { return want[i] < want[j] })
+	i := sort.Search(n, func(i int) bool { return b.TransitionTimes[i] >= from })
+	got := b.TransitionTimes[i:]
+	if len(got) != len(want) {
+		return false
+	}
+	for i, t := range got {
+		z, err := b.Lookup(t)
+		if err != nil || t != want[i] || z != tz.Lookup(t) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package tzif
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestConvertSynthesizesFooter(t *testing.T) {
+	dstEnded := testZone()
+	for _, tc := range []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"prague-fat.tzif", readTestdata(t, "prague-fat.tzif"), "\nCET-1CEST,M3.5.0,M10.5.0/3\n"},
+		{"sydney-slim.tzif", readTestdata(t, "sydney-slim.tzif"), "\nAEST-10AEDT,M10.1.0,M4.1.0/3\n"},
+		{"tokyo-slim.tzif", readTestdata(t, "tokyo-slim.tzif"), "\nJST-9\n"},
+		{"utc.tzif", readTestdata(t, "utc.tzif"), "\nUTC0\n"},
+		// Transitions ending before 2037 mean the last local time type stays in effect.
+		{"DST abolished", testFile{version: 1, v1: dstEnded}.bytes(), "\nCET-1\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := ParseBytes(tc.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v1, err := Convert(f, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v2, err := Convert(v1, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(v2.Footer) != tc.want {
+				t.Errorf("footer %q, want %q", v2.Footer, tc.want)
+			}
+			if f.V2 == nil {
+				return
+			}
+			// Local time after the 32-bit range comes from the footer, it must match the original.
+			for ts := int64(1 << 31); ts < 1<<32; ts += 6 * 3600 {
+				z1, err1 := f.Lookup(ts)
+				z2, err2 := v2.Lookup(ts)
+				if err1 != nil || err2 != nil || z1 != z2 {
+					t.Fatalf("at %d: %+v, %v, want %+v, %v", ts, z2, err2, z1, err1)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertFooterWithoutRules(t *testing.T) {
+	f, err := ParseBytes(readTestdata(t, "prague-fat.tzif"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1, err := Convert(f, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Move the last transition to DST a week later, so the years before follow no common rule.
+	b := &v1.V1
+	for i := len(b.TransitionTimes) - 1; i >= 0; i-- {
+		if b.LocalTimeTypes[b.TransitionTypes[i]].DST != 0 {
+			b.TransitionTimes[i] += 7 * 86400
+			break
+		}
+	}
+	v2, err := Convert(v1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(v2.Footer, []byte("\n\n")) {
+		t.Errorf("footer %q, want an empty TZ string", v2.Footer)
+	}
+}
@@ -0,0 +1,196 @@
+package tzif
+
+import (
+	"bytes"
+	"math"
+	"time"
+)
+
+// Canonical is the content of a TZif file that determines local time, independent of how it is encoded.
+// Files with the same Canonical are interchangeable for readers: they differ at most in the version,
+// the order, duplicates and unused entries of the local time types and designations, the standard/wall
+// and UT/local indicators, the v1 data block, transitions that do not change local time, the transitions
+// at the end that the footer TZ string generates, which fat files list and slim files omit, and the
+// spelling of the TZ string.
+type Canonical struct {
+	// Initial is the local time before the first transition.
+	Initial Zone
+	// Transitions are the transitions of the most precise data block that change local time.
+	Transitions []CanonicalTransition
+	// LeapSeconds are the leap second records of the most precise data block.
+	LeapSeconds []LeapSecond
+	// HasFooter is true if the file has a footer, that is for version 2+ files.
+	HasFooter bool
+	// Footer is the TZ string of the footer, nil if it is empty or there is no footer.
+	Footer *TZString
+}
+
+// CanonicalTransition is a transition of Canonical.
+type CanonicalTransition struct {
+	// Unix is the transition time in seconds since the Unix epoch.
+	Unix int64
+	// Zone is the local time from the transition on. FromFooter is always false.
+	Zone Zone
+}
+
+// Canonical returns the content of f that determines local time. It fails if a transition of the most
+// precise data block does not resolve to a local time type and designation, or the footer cannot be
+// parsed; footer errors are *ParseError at the offset of the footer.
+func (f *File) Canonical() (*Canonical, error) {
+	b := &f.V1
+	c := &Canonical{LeapSeconds: b.LeapSeconds}
+	if f.V2 != nil {
+		b = f.V2
+		c.LeapSeconds, c.HasFooter = b.LeapSeconds, true
+		tz, err := ParseFooter(f.Footer)
+		if err != nil {
+			return nil, &ParseError{Offset: f.FooterOffset(), Field: "footer", Err: err}
+		}
+		c.Footer = tz
+	}
+	keep := len(b.TransitionTimes)
+	if c.Footer != nil {
+		var err error
+		if keep, err = b.RedundantFrom(c.Footer); err != nil {
+			return nil, err
+		}
+	}
+	// Local time type 0 applies before the first transition.
+	var err error
+	if c.Initial, err = b.Lookup(math.MinInt64); err != nil {
+		return nil, err
+	}
+	prev := c.Initial
+	for _, t := range b.TransitionTimes[:keep] {
+		z, err := b.Lookup(t)
+		if err != nil {
+			return nil, err
+		}
+		if z == prev {
+			continue
+		}
+		c.Transitions = append(c.Transitions, CanonicalTransition{Unix: t, Zone: z})
+		prev = z
+	}
+	return c, nil
+}
+
+// Equal reports whether c and other are the same. TZ strings are compared in the form of TZString.String.
+func (c *Canonical) Equal(other *Canonical) bool {
+	if c.Initial != other.Initial || len(c.Transitions) != len(other.Transitions) ||
+		len(c.LeapSeconds) != len(other.LeapSeconds) || c.HasFooter != other.HasFooter ||
+		(c.Footer == nil) != (other.Footer == nil) {
+		return false
+	}
+	for i := range c.Transitions {
+		if c.Transitions[i] != other.Transitions[i] {
+			return false
+		}
+	}
+	for i := range c.LeapSeconds {
+		if c.LeapSeconds[i] != other.LeapSeconds[i] {
+			return false
+		}
+	}
+	return c.Footer == nil || c.Footer.String() == other.Footer.String()
+}
+
+// Equal reports whether f and other determine the same local time and leap seconds, that is whether their
+// Canonical forms are equal. Files whose Canonical form cannot be determined are only equal to a file with
+// identical contents.
+func (f *File) Equal(other *File) bool {
+	c1, err1 := f.Canonical()
+	c2, err2 := other.Canonical()
+	if err1 != nil || err2 != nil {
+		return f.identical(other)
+	}
+	return c1.Equal(c2)
+}
+
+// identical reports whether f and other have identical contents.
+func (f *File) identical(other *File) bool {
+	if (f.V2 == nil) != (other.V2 == nil) || !f.V1.identical(&other.V1) || !bytes.Equal(f.Footer, other.Footer) {
+		return false
+	}
+	return f.V2 == nil || f.V2.identical(other.V2)
+}
+
+// identical reports whether the sections of b and other have identical contents.
+func (b *DataBlock) identical(other *DataBlock) bool {
+	if b.Header.Version != other.Header.Version || len(b.TransitionTimes) != len(other.TransitionTimes) ||
+		len(b.LocalTimeTypes) != len(other.LocalTimeTypes) || len(b.LeapSeconds) != len(other.LeapSeconds) ||
+		len(b.IsStd) != len(other.IsStd) || len(b.IsUT) != len(other.IsUT) {
+		return false
+	}
+	for i := range b.TransitionTimes {
+		if b.TransitionTimes[i] != other.TransitionTimes[i] {
+			return false
+		}
+	}
+	for i := range b.LocalTimeTypes {
+		if b.LocalTimeTypes[i] != other.LocalTimeTypes[i] {
+			return false
+		}
+	}
+	for i := range b.LeapSeconds {
+		if b.LeapSeconds[i] != other.LeapSeconds[i] {
+			return false
+		}
+	}
+	for i := range b.IsStd {
+		if b.IsStd[i] != other.IsStd[i] {
+			return false
+		}
+	}
+	for i := range b.IsUT {
+		if b.IsUT[i] != other.IsUT[i] {
+			return false
+		}
+	}
+	return bytes.Equal(b.TransitionTypes, other.TransitionTypes) && bytes.Equal(b.Designations, other.Designations)
+}
+
+// RedundantFrom returns the index of the first of the transitions at the end of b that tz generates.
+// Dropping them does not change the local time at any instant, since the footer applies after the last
+// remaining transition and agrees with the transition table from there on.
+func (b *DataBlock) RedundantFrom(tz *TZString) (int, error) {
+	n := len(b.TransitionTimes)
+	if n == 0 {
+		return 0, nil
+	}
+	first, last := b.TransitionTimes[0], b.TransitionTimes[n-1]
+	// Both the table and the footer are constant between their transitions, so comparing them
+	// at each transition of either suffices. Find the latest instant at which they disagree.
+	points := append([]int64(nil), b.TransitionTimes...)
+	for year := time.Unix(first, 0).UTC().Year(); year <= time.Unix(last, 0).UTC().Year(); year++ {
+		start, end, ok := tz.Transitions(year)
+		if !ok {
+			break
+		}
+		for _, t := range []int64{start, end} {
+			if t >= first && t <= last {
+				points = append(points, t)
+			}
+		}
+	}
+	var disagree int64
+	found := false
+	for _, t := range points {
+		z, err := b.Lookup(t)
+		if err != nil {
+			return 0, err
+		}
+		fz := tz.Lookup(t)
+		if (z.Name != fz.Name || z.UTOff != fz.UTOff || z.DST != fz.DST) && (!found || t > disagree) {
+			disagree, found = t, true
+		}
+	}
+	// Keep the transitions up to and including the first one after the last disagreement.
+	keep := 1
+	if found {
+		for keep <= n && b.TransitionTimes[keep-1] <= disagree {
+			keep++
+		}
+	}
+	return min(keep, n), nil
+}
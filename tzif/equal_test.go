@@ -0,0 +1,103 @@
+package tzif
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	parse := func(tf testFile) *File {
+		t.Helper()
+		f, err := Parse(bytes.NewReader(tf.bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+	// The same data as testZone with the designations and records in another order and an unused record.
+	// Local time type 0 stays first, it applies before the first transition.
+	reordered := testBlock{
+		times:   []int64{-100, 100},
+		types:   []uint8{2, 0},
+		records: []LocalTimeType{{UTOff: 3600, Idx: 5}, {UTOff: 0, Idx: 9}, {UTOff: 7200, DST: 1, Idx: 0}},
+		desigs:  "CEST\x00CET\x00UTC\x00",
+		leaps:   []LeapSecond{{Occur: 78796800, Corr: 1}},
+		isStd:   []uint8{1, 0, 0},
+		isUT:    []uint8{1, 0, 0},
+	}
+	otherTime := testZone()
+	otherTime.times = []int64{-100, 101}
+	otherIndicator := testZone()
+	otherIndicator.isUT = []uint8{0, 0}
+	noOp := testZone()
+	noOp.times, noOp.types = append(noOp.times, 200), append(noOp.types, 0)
+	otherLeap := testZone()
+	otherLeap.leaps = nil
+	v2 := testZone()
+
+	slim := parse(testSlim(testZone()))
+	for _, tc := range []struct {
+		name  string
+		other testFile
+		want  bool
+	}{
+		{"same bytes", testSlim(testZone()), true},
+		{"reordered", testSlim(reordered), true},
+		{"footer spelled differently", testFile{version: 2, v1: testPlaceholder, v2: &v2, footer: "\nCET-1CEST-2,M3.5.0/2,M10.5.0/03:00\n"}, true},
+		{"fat", testFile{version: 2, v1: testZone(), v2: &v2, footer: testSlim(testZone()).footer}, true},
+		{"other version", testFile{version: 3, v1: testPlaceholder, v2: &v2, footer: testSlim(testZone()).footer}, true},
+		{"other indicator", testSlim(otherIndicator), true},
+		{"no-op transition", testSlim(noOp), true},
+		{"other footer", testFile{version: 2, v1: testPlaceholder, v2: &v2, footer: "\nCET-1\n"}, false},
+		{"other transition time", testSlim(otherTime), false},
+		{"other leap second", testSlim(otherLeap), false},
+		{"version 1", testFile{version: 1, v1: testZone()}, false},
+	} {
+		other := parse(tc.other)
+		if got := slim.Equal(other); got != tc.want {
+			t.Errorf("%s: Equal %t, want %t", tc.name, got, tc.want)
+		}
+		if got := other.Equal(slim); got != tc.want {
+			t.Errorf("%s: Equal reversed %t, want %t", tc.name, got, tc.want)
+		}
+	}
+
+	// Files with unresolvable indexes are compared byte by byte.
+	bad := testZone()
+	bad.types = []uint8{1, 5}
+	badFile, err := Options{Lenient: true}.Parse(bytes.NewReader(testSlim(bad).bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !badFile.Equal(badFile) || badFile.Equal(slim) {
+		t.Errorf("file with a transition type out of range compared wrongly")
+	}
+}
+
+func TestEqualFatSlim(t *testing.T) {
+	fat, err := ParseBytes(readTestdata(t, "prague-fat.tzif"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	slim, err := ParseBytes(readTestdata(t, "prague-slim.tzif"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := ParseBytes(readTestdata(t, "sydney-slim.tzif"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fat.Equal(slim) || !slim.Equal(fat) {
+		t.Errorf("fat and slim files of the same zone are not equal")
+	}
+	if fat.Equal(other) {
+		t.Errorf("files of different zones are equal")
+	}
+	c, err := fat.Canonical()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(c.Transitions); n == 0 || n >= len(fat.V2.TransitionTimes) {
+		t.Errorf("%d canonical transitions, want fewer than the %d of the fat file", n, len(fat.V2.TransitionTimes))
+	}
+}
@@ -0,0 +1,87 @@
+package tzif
+
+import (
+	"io"
+	"math"
+)
+
+// ReadFooter reads the footer of a TZif file from r, see Options.ReadFooter.
+func ReadFooter(r io.Reader) ([]byte, error) {
+	return Options{}.ReadFooter(r)
+}
+
+// ReadFooter returns the footer of the TZif file read from r, as File.Footer of the file Parse returns:
+// the data following the v2+ data block, or nil for version 1 files. If r is an io.ReadSeeker, the data
+// blocks are not read: their sizes are derived from the headers and skipped by seeking, so only the two
+// headers and the footer are read, starting at the current offset of r. Other readers, and files whose
+// data blocks the input is too short for, are parsed in full, so that errors are reported as by Parse.
+// The footer is not validated, see ParseFooter.
+func (o Options) ReadFooter(r io.Reader) ([]byte, error) {
+	rs, ok := r.(io.ReadSeeker)
+	if !ok {
+		return o.parseFooter(r)
+	}
+	start, err := rs.Seek(0, io.SeekCurrent)
+	if err != nil {
+		// Pipes and other files that cannot seek.
+		return o.parseFooter(r)
+	}
+	end, err := rs.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	size := end - start
+	p := blockReader{r: rs, end: -1}
+	// seek moves to offset off of the file and continues reading there.
+	seek := func(off int64) error {
+		_, err := rs.Seek(start+off, io.SeekStart)
+		p.off = off
+		return err
+	}
+	if err := seek(0); err != nil {
+		return nil, err
+	}
+	h, err := p.header(o, "v1")
+	if err != nil {
+		return nil, err
+	}
+	if h.Version == 1 {
+		return nil, nil
+	}
+	v2Offset := uint64(headerSize) + dataBlockSize(h, 4)
+	if v2Offset+headerSize > uint64(size) {
+		return o.parseFooterAt(rs, start)
+	}
+	if err := seek(int64(v2Offset)); err != nil {
+		return nil, err
+	}
+	h, err = p.header(o, "v2+")
+	if err != nil {
+		return nil, err
+	}
+	footerOffset := v2Offset + headerSize + dataBlockSize(h, 8)
+	if footerOffset > uint64(size) {
+		return o.parseFooterAt(rs, start)
+	}
+	if err := seek(int64(footerOffset)); err != nil {
+		return nil, err
+	}
+	return p.read(o, math.MaxUint64, "footer")
+}
+
+// parseFooterAt parses the file starting at offset start of rs in full and returns its footer.
+func (o Options) parseFooterAt(rs io.ReadSeeker, start int64) ([]byte, error) {
+	if _, err := rs.Seek(start, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return o.parseFooter(rs)
+}
+
+// parseFooter parses the file read from r in full and returns its footer.
+func (o Options) parseFooter(r io.Reader) ([]byte, error) {
+	f, err := o.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	return f.Footer, nil
+}
@@ -0,0 +1,61 @@
+package tzif
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	io.ReadSeeker
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.ReadSeeker.Read(p)
+	c.n += n
+	return n, err
+}
+
+func TestReadFooter(t *testing.T) {
+	names, err := testdata.ReadDir("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range names {
+		data := readTestdata(t, entry.Name())
+		f, err := ParseBytes(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := &countingReader{ReadSeeker: bytes.NewReader(data)}
+		footer, err := ReadFooter(r)
+		if err != nil || !bytes.Equal(footer, f.Footer) {
+			t.Errorf("%s: got %q, %v, want %q", entry.Name(), footer, err, f.Footer)
+		}
+		if want := 2*headerSize + len(f.Footer); r.n != want {
+			t.Errorf("%s: read %d bytes, want only the headers and footer, %d bytes", entry.Name(), r.n, want)
+		}
+		// Readers that cannot seek are parsed in full.
+		footer, err = ReadFooter(struct{ io.Reader }{bytes.NewReader(data)})
+		if err != nil || !bytes.Equal(footer, f.Footer) {
+			t.Errorf("%s without seeking: got %q, %v, want %q", entry.Name(), footer, err, f.Footer)
+		}
+	}
+
+	// The file starts at the current offset.
+	data := readTestdata(t, "tokyo-slim.tzif")
+	r := bytes.NewReader(append([]byte("junk"), data...))
+	r.Seek(4, io.SeekStart)
+	if footer, err := ReadFooter(r); err != nil || string(footer) != "\nJST-9\n" {
+		t.Errorf("at an offset: got %q, %v", footer, err)
+	}
+
+	// A truncated file is reported as by Parse.
+	truncated := data[:len(data)-20]
+	_, want := ParseBytes(truncated)
+	if _, err := ReadFooter(bytes.NewReader(truncated)); err == nil || want == nil || err.Error() != want.Error() {
+		t.Errorf("truncated: got %v, want %v", err, want)
+	}
+}
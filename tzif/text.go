@@ -0,0 +1,38 @@
+package tzif
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// DefaultTimeLayout is the layout in which TextFormatter renders transition times in UTC by default.
+const DefaultTimeLayout = "2006-01-02T15:04:05"
+
+// TextFormatter writes the transitions of a View as text, one per line, in the form tzif2text prints them,
+// for example "1711846800 (2024-03-31T01:00:00 UTC) -> CEST utoff=7200 dst=1".
+type TextFormatter struct {
+	// TimeFormatter renders a transition time given in seconds since the Unix epoch. If nil, the time
+	// is rendered in UTC with DefaultTimeLayout followed by " UTC".
+	TimeFormatter func(int64) string
+}
+
+// formatTime renders t with TimeFormatter or the default layout.
+func (tf *TextFormatter) formatTime(t int64) string {
+	if tf.TimeFormatter != nil {
+		return tf.TimeFormatter(t)
+	}
+	return time.Unix(t, 0).UTC().Format(DefaultTimeLayout) + " UTC"
+}
+
+// WriteTransitions writes a line for each transition of v.
+func (tf *TextFormatter) WriteTransitions(w io.Writer, v *View) error {
+	for _, t := range v.Transitions {
+		_, err := fmt.Fprintf(w, "%d (%s) -> %s utoff=%d dst=%d\n", t.Unix, tf.formatTime(t.Unix),
+			t.Type.Designation, t.Type.UTOff, t.Type.DST)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package tzif
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTextFormatter(t *testing.T) {
+	f, err := ParseBytes(readTestdata(t, "tokyo-slim.tzif"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := NewView(f, "Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := (&TextFormatter{}).WriteTransitions(&buf, v); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(buf.String(), "\n")
+	if want := "-577962000 (1951-09-08T15:00:00 UTC) -> JST utoff=32400 dst=0"; lines[len(lines)-2] != want {
+		t.Errorf("default: got %q, want %q", lines[len(lines)-2], want)
+	}
+
+	buf.Reset()
+	tokyo := time.FixedZone("JST", 9*3600)
+	tf := &TextFormatter{TimeFormatter: func(t int64) string {
+		return fmt.Sprintf("%s, %d", time.Unix(t, 0).In(tokyo).Format("2 Jan 2006 15:04 MST"), t%2)
+	}}
+	if err := tf.WriteTransitions(&buf, v); err != nil {
+		t.Fatal(err)
+	}
+	lines = strings.Split(buf.String(), "\n")
+	if want := "-577962000 (9 Sep 1951 00:00 JST, 0) -> JST utoff=32400 dst=0"; lines[len(lines)-2] != want {
+		t.Errorf("TimeFormatter: got %q, want %q", lines[len(lines)-2], want)
+	}
+	if len(lines) != len(v.Transitions)+1 {
+		t.Errorf("got %d lines, want %d", len(lines)-1, len(v.Transitions))
+	}
+}
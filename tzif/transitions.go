@@ -0,0 +1,124 @@
+package tzif
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Transitions gives access to the transitions of the most precise data block of a TZif file without
+// decoding the whole file as Parse does. Only the headers are decoded upfront, each transition and the
+// local time type it selects are decoded from the raw bytes when requested, so that for example the
+// first and last transition of many files can be found cheaply.
+type Transitions struct {
+	// b holds the header and designations of the data block, the other sections are decoded on demand.
+	b        DataBlock
+	timeSize int
+	// times, types, records, isStd and isUT are the raw sections of the data block.
+	times, types, records, isStd, isUT []byte
+}
+
+// NewTransitions returns the transitions of the TZif file data. It fails if a header is malformed or
+// the data block does not fit in data, the transitions themselves are only checked by At.
+// data is not copied, it must not be modified while the Transitions are used.
+func NewTransitions(data []byte) (*Transitions, error) {
+	var o Options
+	off := int64(0)
+	header := func() (Header, error) {
+		if int64(len(data)) < off+headerSize {
+			return Header{}, &ParseError{Offset: off, Err: fmt.Errorf("missing header")}
+		}
+		rest, h, err := o.parseHeader(data[off : off+headerSize])
+		if err != nil {
+			p := blockReader{off: off}
+			return h, p.parsed(data[off:off+headerSize], rest, err)
+		}
+		off += headerSize
+		return h, nil
+	}
+	h, err := header()
+	if err != nil {
+		return nil, err
+	}
+	timeSize := uint64(4)
+	if h.Version > 1 {
+		if err := checkFit(data[off:], h, timeSize); err != nil {
+			err.Offset += off
+			return nil, err
+		}
+		off += int64(dataBlockSize(h, timeSize))
+		if h, err = header(); err != nil {
+			return nil, err
+		}
+		timeSize = 8
+	}
+	block := data[off:]
+	if err := checkFit(block, h, timeSize); err != nil {
+		err.Offset += off
+		return nil, err
+	}
+	t := &Transitions{b: DataBlock{Header: h}, timeSize: int(timeSize)}
+	section := func(size uint64) []byte {
+		s := block[:size]
+		block = block[size:]
+		return s
+	}
+	t.times = section(uint64(h.TimeCnt) * timeSize)
+	t.types = section(uint64(h.TimeCnt))
+	t.records = section(uint64(h.TypeCnt) * 6)
+	t.b.Designations = section(uint64(h.CharCnt))
+	section(uint64(h.LeapCnt) * (timeSize + 4))
+	t.isStd = section(uint64(h.IsStdCnt))
+	t.isUT = section(uint64(h.IsUTCnt))
+	return t, nil
+}
+
+// Header returns the header of the data block the transitions are read from.
+func (t *Transitions) Header() Header {
+	return t.b.Header
+}
+
+// Len returns the number of transitions.
+func (t *Transitions) Len() int {
+	return len(t.types)
+}
+
+// At decodes the transition with index i. It fails if i is not in the range 0 to Len()-1
+// or the local time type or its designation is out of range.
+func (t *Transitions) At(i int) (Transition, error) {
+	if i < 0 || i >= t.Len() {
+		return Transition{}, fmt.Errorf("transition %d out of range", i)
+	}
+	raw := t.times[i*t.timeSize:]
+	var ts int64
+	if t.timeSize == 4 {
+		ts = int64(int32(binary.BigEndian.Uint32(raw)))
+	} else {
+		ts = int64(binary.BigEndian.Uint64(raw))
+	}
+	typ, err := t.Type(int(t.types[i]))
+	if err != nil {
+		return Transition{}, fmt.Errorf("transition %d: %v", i, err)
+	}
+	return Transition{Index: i, Unix: ts, Type: typ}, nil
+}
+
+// Type decodes the local time type with index i, including its designation and indicators.
+func (t *Transitions) Type(i int) (*Type, error) {
+	if i < 0 || i >= len(t.records)/6 {
+		return nil, fmt.Errorf("local time type %d out of range", i)
+	}
+	rec := t.records[6*i:]
+	lt := LocalTimeType{UTOff: int32(binary.BigEndian.Uint32(rec[0:4])), DST: rec[4], Idx: rec[5]}
+	desig, err := t.b.Designation(lt.Idx)
+	if err != nil {
+		return nil, fmt.Errorf("local time type %d: %v", i, err)
+	}
+	typ := &Type{LocalTimeType: lt, Index: i, Designation: desig}
+	if i < len(t.isStd) {
+		typ.IsStd = t.isStd[i] != 0
+	}
+	if i < len(t.isUT) {
+		typ.IsUT = t.isUT[i] != 0
+	}
+	return typ, nil
+}
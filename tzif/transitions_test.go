@@ -0,0 +1,64 @@
+package tzif
+
+import (
+	"testing"
+)
+
+func TestTransitionsMatchParse(t *testing.T) {
+	v2 := testZone()
+	for name, data := range map[string][]byte{
+		"prague-fat.tzif":  readTestdata(t, "prague-fat.tzif"),
+		"prague-slim.tzif": readTestdata(t, "prague-slim.tzif"),
+		"right-utc.tzif":   readTestdata(t, "right-utc.tzif"),
+		"built v1":         testFile{version: 1, v1: testZone()}.bytes(),
+		"built fat":        testFile{version: 3, v1: testPlaceholder, v2: &v2, footer: "\n\n"}.bytes(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			f, err := ParseBytes(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v, err := NewView(f, name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ts, err := NewTransitions(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b := &f.V1
+			if f.V2 != nil {
+				b = f.V2
+			}
+			if ts.Header() != b.Header {
+				t.Errorf("header %+v, want %+v", ts.Header(), b.Header)
+			}
+			if ts.Len() != len(v.Transitions) {
+				t.Fatalf("%d transitions, want %d", ts.Len(), len(v.Transitions))
+			}
+			for i, want := range v.Transitions {
+				got, err := ts.At(i)
+				if err != nil {
+					t.Fatalf("transition %d: %v", i, err)
+				}
+				if got.Index != want.Index || got.Unix != want.Unix || *got.Type != *want.Type {
+					t.Fatalf("transition %d: %+v %+v, want %+v %+v", i, got, got.Type, want, want.Type)
+				}
+			}
+			for i, want := range v.Types {
+				got, err := ts.Type(i)
+				if err != nil || *got != want {
+					t.Errorf("type %d: %+v, %v, want %+v", i, got, err, want)
+				}
+			}
+			for _, i := range []int{-1, ts.Len()} {
+				if _, err := ts.At(i); err == nil {
+					t.Errorf("transition %d decoded without error", i)
+				}
+			}
+			if _, err := ts.Type(len(v.Types)); err == nil {
+				t.Errorf("type %d decoded without error", len(v.Types))
+			}
+		})
+	}
+}
@@ -0,0 +1,1020 @@
+// Package tzif parses time zone information files as specified in https://tools.ietf.org/html/rfc8536
+// and its successor https://www.rfc-editor.org/rfc/rfc9636, which adds version 4.
+//
+// Parse reads a file from an io.Reader, ParseBytes parses one held in memory, and Options configure both.
+// The parts of a file defined by the RFC map to the types of the package as follows:
+// a header is Header and a data block is DataBlock; transition times are DataBlock.TransitionTimes,
+// Unix times as int64 for both the 32-bit and 64-bit data blocks; local time type records are LocalTimeType,
+// leap second records LeapSecond; and the footer is File.Footer, whose TZ string ParseFooter parses into TZString.
+package tzif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Magic is the four-octet sequence every TZif file starts with.
+const Magic = "TZif"
+
+// File is a parsed TZif file.
+type File struct {
+	// V1 is the version 1 data block, with 32-bit times.
+	V1 DataBlock
+	// V2 is the version 2+ data block, with 64-bit times. It is nil for version 1 files.
+	V2 *DataBlock
+	// Footer is the raw footer including the enclosing newlines. It is nil for version 1 files.
+	Footer []byte
+	// Unreadable is the number of bytes described by the headers that best-effort mode could not read,
+	// because the input ends before them or the v2+ header was found earlier than they would end.
+	Unreadable uint64
+	// Errors holds the errors of the parts of the file outside the data blocks that best-effort mode
+	// could not read, such as a missing v2+ header. V2 is nil if its header is missing.
+	Errors []error
+}
+
+// Header is a TZif header preceding a data block.
+type Header struct {
+	// Version is the version of the file, 1 to 4.
+	Version uint8
+	// IsUTCnt, IsStdCnt, LeapCnt, TimeCnt, TypeCnt and CharCnt are the counts of the data block sections.
+	IsUTCnt, IsStdCnt, LeapCnt, TimeCnt, TypeCnt, CharCnt uint32
+}
+
+// DataBlock is a header together with the data block it describes.
+type DataBlock struct {
+	Header          Header
+	TransitionTimes []int64
+	// TransitionTypes contains an index into LocalTimeTypes for each transition time.
+	TransitionTypes []uint8
+	LocalTimeTypes  []LocalTimeType
+	// Designations is the raw sequence of NUL-terminated time zone designations.
+	Designations []byte
+	LeapSeconds  []LeapSecond
+	// IsStd contains the standard/wall indicators, true meaning standard time.
+	IsStd []bool
+	// IsUT contains the UT/local indicators, true meaning UT.
+	IsUT []bool
+	// Errors holds the errors of sections skipped in best-effort mode.
+	// The slices of a skipped section contain the entries decoded before the error.
+	Errors map[Section]error
+	// Warnings holds the violations tolerated in lenient mode.
+	Warnings []error
+}
+
+// LocalTimeType is a local time type record.
+type LocalTimeType struct {
+	// UTOff is the number of seconds to be added to UT to determine local time.
+	UTOff int32
+	// DST is 1 if local time is daylight saving time, 0 otherwise.
+	DST uint8
+	// Idx is the byte offset of the time zone designation in DataBlock.Designations.
+	Idx uint8
+}
+
+// Designation returns the NUL-terminated time zone designation starting at byte offset idx of the designations.
+func (b *DataBlock) Designation(idx uint8) (string, error) {
+	if int(idx) >= len(b.Designations) {
+		return "", fmt.Errorf("idx %d out of range (0..%d)", idx, len(b.Designations)-1)
+	}
+	end := bytes.IndexByte(b.Designations[idx:], 0)
+	if end < 0 {
+		return "", fmt.Errorf("designation at idx %d is not NUL-terminated", idx)
+	}
+	return string(b.Designations[idx : int(idx)+end]), nil
+}
+
+// DesignationAt returns the time zone designation starting at byte offset idx of the designations of the
+// most precise data block, as referenced by its local time type records. idx may point into the middle of
+// a designation to share its suffix, as zic does. See DataBlock.Designation for the errors.
+func (f *File) DesignationAt(idx byte) (string, error) {
+	b := &f.V1
+	if f.V2 != nil {
+		b = f.V2
+	}
+	return b.Designation(idx)
+}
+
+// FooterOffset returns the offset of the footer from the start of the file, derived from the counts in
+// the headers. It is 0 for version 1 files, which have no footer.
+func (f *File) FooterOffset() int64 {
+	if f.V2 == nil {
+		return 0
+	}
+	return int64(2*headerSize + dataBlockSize(f.V1.Header, 4) + dataBlockSize(f.V2.Header, 8))
+}
+
+// CheckTransitionOrder reports an error if the transition times are not strictly increasing.
+func (b *DataBlock) CheckTransitionOrder() error {
+	for i := 1; i < len(b.TransitionTimes); i++ {
+		if b.TransitionTimes[i] <= b.TransitionTimes[i-1] {
+			return fmt.Errorf("transition time %d (%d) is not greater than transition time %d (%d)",
+				i, b.TransitionTimes[i], i-1, b.TransitionTimes[i-1])
+		}
+	}
+	return nil
+}
+
+// minLeapSecondGap is the minimum difference between consecutive leap second occurrences,
+// 28 days minus the inserted leap second.
+const minLeapSecondGap = 28*86400 - 1
+
+// CheckLeapSeconds reports an error if the leap second records violate RFC 8536: occurrences must be
+// strictly increasing and at least 28 days apart, and the correction must start at +1 or -1 and
+// change by exactly one between consecutive records. Version 4 files (RFC 9636) may start with
+// another correction if the table is truncated at the start, and the last record may repeat
+// the correction of the previous one to mark the expiration of the table.
+func (b *DataBlock) CheckLeapSeconds() error {
+	for i, ls := range b.LeapSeconds {
+		if i == 0 {
+			if ls.Corr != 1 && ls.Corr != -1 && b.Header.Version < 4 {
+				return fmt.Errorf("leap second record 0 has corr %d, must be 1 or -1", ls.Corr)
+			}
+			continue
+		}
+		prev := b.LeapSeconds[i-1]
+		if ls.Occur <= prev.Occur {
+			return fmt.Errorf("leap second record %d occurs at %d, not after record %d (%d)", i, ls.Occur, i-1, prev.Occur)
+		}
+		if ls.Occur-prev.Occur < minLeapSecondGap {
+			return fmt.Errorf("leap second record %d occurs %d seconds after record %d, must be at least %d",
+				i, ls.Occur-prev.Occur, i-1, minLeapSecondGap)
+		}
+		if _, ok := b.LeapSecondsExpire(); ok && i == len(b.LeapSeconds)-1 {
+			continue
+		}
+		if diff := int64(ls.Corr) - int64(prev.Corr); diff != 1 && diff != -1 {
+			return fmt.Errorf("leap second record %d changes corr from %d to %d, must change by 1 or -1", i, prev.Corr, ls.Corr)
+		}
+	}
+	return nil
+}
+
+// LeapSecondsExpire returns the expiration time of the leap second table of a version 4 file,
+// given by a last record with the same correction as the previous one. ok is false if there is none.
+func (b *DataBlock) LeapSecondsExpire() (expires int64, ok bool) {
+	n := len(b.LeapSeconds)
+	if b.Header.Version < 4 || n < 2 || b.LeapSeconds[n-1].Corr != b.LeapSeconds[n-2].Corr {
+		return 0, false
+	}
+	return b.LeapSeconds[n-1].Occur, true
+}
+
+// LeapSecondsTruncated reports whether the leap second table of a version 4 file is truncated at the start,
+// that is its first record has a correction other than +1 or -1.
+func (b *DataBlock) LeapSecondsTruncated() bool {
+	return b.Header.Version >= 4 && len(b.LeapSeconds) > 0 && b.LeapSeconds[0].Corr != 1 && b.LeapSeconds[0].Corr != -1
+}
+
+// LeapSecond is a leap-second record.
+type LeapSecond struct {
+	// Occur is the time at which the leap second occurs.
+	Occur int64
+	// Corr is the total correction after it is applied.
+	Corr int32
+}
+
+// Section identifies a section of a data block.
+type Section string
+
+const (
+	SectionTransitionTimes Section = "transition times"
+	SectionTransitionTypes Section = "transition types"
+	SectionLocalTimeTypes  Section = "local time type records"
+	SectionDesignations    Section = "time zone designations"
+	SectionLeapSeconds     Section = "leap second records"
+	SectionIsStd           Section = "standard/wall indicators"
+	SectionIsUT            Section = "UT/local indicators"
+)
+
+// Options configure parsing.
+type Options struct {
+	// BestEffort continues parsing after a malformed data block section as long as the sections
+	// that follow can still be located. The errors are recorded in DataBlock.Errors.
+	// A truncated file is decoded as far as it goes, the missing sections are recorded too.
+	// If the v1 data block runs past the start of the v2+ header, the v2+ header is looked for
+	// in the v1 data block, so that a corrupt v1 header does not prevent decoding the v2+ data block.
+	BestEffort bool
+	// Strict enforces the requirements of RFC 8536 that are otherwise ignored:
+	// the reserved bytes are zero, typecnt and charcnt are at least one, transition times are
+	// strictly increasing and leap second records are well-formed (see DataBlock.CheckLeapSeconds).
+	Strict bool
+	// Lenient tolerates violations that do not prevent decoding the rest of the file:
+	// a v2+ header version differing from the v1 one, transition types and designation indexes
+	// out of range, data after the last designation and malformed standard/wall and UT/local
+	// indicators. The violations are recorded in DataBlock.Warnings.
+	// Lenient and Strict are mutually exclusive.
+	Lenient bool
+	// MaxCount, if not zero, is the largest value accepted for any count in a header.
+	MaxCount uint32
+	// SkipLeapSeconds skips the leap second records instead of decoding them into DataBlock.LeapSeconds,
+	// for callers that do not need them. The sections following them are still found, as the records
+	// are read past. The strict checks of the records are skipped too.
+	SkipLeapSeconds bool
+	// MaxSize, if not zero, is the largest number of bytes read from the input.
+	// A longer input is rejected, including data following the footer.
+	MaxSize int64
+}
+
+// Parse parses a TZif file read from r.
+func Parse(r io.Reader) (*File, error) {
+	return Options{}.Parse(r)
+}
+
+// ParseBytes parses the TZif file data, see Options.Parse.
+func ParseBytes(data []byte) (*File, error) {
+	return Options{}.Parse(bytes.NewReader(data))
+}
+
+// Parse parses a TZif file read from r.
+// The file is read one header or data block at a time, so parsing stops as soon as a malformed header or
+// data block is found. Such errors are reported as *ParseError. Data following the footer is not checked,
+// it is read as part of File.Footer.
+func (o Options) Parse(r io.Reader) (*File, error) {
+	var f File
+	p := blockReader{r: r, end: -1}
+	h, err := p.header(o, "v1")
+	if err != nil {
+		return nil, err
+	}
+	err = p.dataBlock(o, "v1", h, time32, 4, &f)
+	if err != nil {
+		return nil, err
+	}
+	if h.Version == 1 {
+		return &f, nil
+	}
+	versionOffset := p.off + int64(len(Magic))
+	h, err = p.header(o, "v2+")
+	if err != nil {
+		if o.BestEffort && p.end >= 0 {
+			// The input ends before the v2+ data block.
+			if p.off == p.end {
+				err = &ParseError{Offset: p.off, Field: "v2+ header", Err: fmt.Errorf("missing, the input ends before it")}
+			}
+			f.Errors = append(f.Errors, err)
+			return &f, nil
+		}
+		return nil, err
+	}
+	f.V2 = new(DataBlock)
+	if h.Version != f.V1.Header.Version {
+		err := fmt.Errorf("v2+ header version %d differs from v1 header version %d", h.Version, f.V1.Header.Version)
+		if err = o.tolerate(f.V2, err); err != nil {
+			return nil, &ParseError{Offset: versionOffset, Field: "version",
+				Expected: strconv.Itoa(int(f.V1.Header.Version)), Actual: strconv.Itoa(int(h.Version)), Err: err}
+		}
+	}
+	err = p.dataBlock(o, "v2+", h, time64, 8, &f)
+	if err != nil {
+		return nil, err
+	}
+	f.Footer, err = p.read(o, math.MaxUint64, "footer")
+	if err != nil {
+		return nil, err
+	}
+	if o.BestEffort && len(f.Footer) == 0 {
+		f.Footer = nil
+		f.Errors = append(f.Errors, &ParseError{Offset: p.off, Field: "footer", Err: fmt.Errorf("missing, the input ends before it")})
+	}
+	return &f, nil
+}
+
+// Split splits data into the TZif files concatenated in it. A file ends after the newline closing
+// its footer, or after its v1 data block for version 1 files, if a complete file with well-formed
+// headers follows. Other data following a file, such as a truncated file, is left in the last file
+// for Parse to report or, after a version 1 file, to ignore.
+func Split(data []byte) [][]byte {
+	var files [][]byte
+	for {
+		n := fileSize(data)
+		if n == 0 || n >= uint64(len(data)) {
+			return append(files, data)
+		}
+		if next := fileSize(data[n:]); next == 0 || next > uint64(len(data))-n {
+			return append(files, data)
+		}
+		files = append(files, data[:n])
+		data = data[n:]
+	}
+}
+
+// fileSize returns the length of the TZif file at the start of data, or 0 if data does not hold
+// a complete file with well-formed headers.
+func fileSize(data []byte) uint64 {
+	var o Options
+	_, h, err := o.parseHeader(data)
+	if err != nil {
+		return 0
+	}
+	n := headerSize + dataBlockSize(h, 4)
+	if h.Version == 1 || n >= uint64(len(data)) {
+		return n
+	}
+	_, h, err = o.parseHeader(data[n:])
+	if err != nil {
+		return 0
+	}
+	n += headerSize + dataBlockSize(h, 8)
+	if n >= uint64(len(data)) || data[n] != '\n' {
+		return 0
+	}
+	end := bytes.IndexByte(data[n+1:], '\n')
+	if end < 0 {
+		return 0
+	}
+	return n + uint64(end) + 2
+}
+
+// ParseError is an error in the input of Parse.
+type ParseError struct {
+	// Offset is the offset in bytes from the start of the input where the error was found.
+	Offset int64
+	// Field is the name of the header field the error relates to, such as "timecnt", or empty.
+	Field string
+	// Expected and Actual describe the value the field should have and the value found,
+	// if the error is about the value of a field. They are empty otherwise.
+	Expected, Actual string
+	Err              error
+}
+
+func (e *ParseError) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("offset %d: %s: %v", e.Offset, e.Field, e.Err)
+	}
+	return fmt.Sprintf("offset %d: %v", e.Offset, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// headerSize is the length of a TZif header in bytes.
+const headerSize = 44
+
+// countNames are the names of the counts in a header, in the order they are stored starting at countsOffset.
+var countNames = []string{"isutcnt", "isstdcnt", "leapcnt", "timecnt", "typecnt", "charcnt"}
+
+// countsOffset is the offset of the first count in a header.
+const countsOffset = 20
+
+// blockReader reads the headers and data blocks of a TZif file from r, keeping track of the offset.
+type blockReader struct {
+	r   io.Reader
+	off int64
+	// end is the offset at which r ended, -1 while it has not.
+	end int64
+}
+
+// read reads up to n bytes of the part of the file named what, fewer only if r ends first.
+// The buffer grows as data arrives, so a corrupt count does not allocate more than the input holds.
+// If the input continues past o.MaxSize, read returns a ParseError instead.
+func (p *blockReader) read(o Options, n uint64, what string) ([]byte, error) {
+	n = min(n, math.MaxInt64)
+	limited := false
+	if o.MaxSize > 0 && n > uint64(max(o.MaxSize-p.off, 0)) {
+		// Read one more byte than allowed to tell a short input from a long one.
+		n = uint64(max(o.MaxSize-p.off, 0)) + 1
+		limited = true
+	}
+	data, err := io.ReadAll(io.LimitReader(p.r, int64(n)))
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(data)) < n {
+		p.end = p.off + int64(len(data))
+	}
+	if limited && uint64(len(data)) == n {
+		return nil, &ParseError{Offset: p.off, Err: fmt.Errorf("%s exceeds the input size limit of %d bytes", what, o.MaxSize)}
+	}
+	return data, nil
+}
+
+// parsed wraps err in a ParseError located where rest starts within data, which was read at p.off.
+// If err already is a ParseError, its offset is taken as relative to the start of data instead.
+func (p *blockReader) parsed(data, rest []byte, err error) error {
+	if err == nil {
+		return nil
+	}
+	if pe, ok := err.(*ParseError); ok {
+		pe.Offset += p.off
+		return pe
+	}
+	return &ParseError{Offset: p.off + int64(len(data)-len(rest)), Err: err}
+}
+
+func (p *blockReader) header(o Options, name string) (Header, error) {
+	data, err := p.read(o, headerSize, name+" header")
+	if err != nil {
+		return Header{}, err
+	}
+	rest, h, err := o.parseHeader(data)
+	if err != nil {
+		if len(rest) == 0 {
+			// The strict checks apply to the header as a whole.
+			rest = data
+		}
+		return h, p.parsed(data, rest, err)
+	}
+	if o.MaxCount > 0 {
+		for i, cnt := range []uint32{h.IsUTCnt, h.IsStdCnt, h.LeapCnt, h.TimeCnt, h.TypeCnt, h.CharCnt} {
+			if cnt > o.MaxCount {
+				return h, &ParseError{Offset: p.off + countsOffset + 4*int64(i), Field: countNames[i],
+					Expected: fmt.Sprintf("at most %d", o.MaxCount), Actual: strconv.FormatUint(uint64(cnt), 10),
+					Err: fmt.Errorf("%d exceeds the limit of %d", cnt, o.MaxCount)}
+			}
+		}
+	}
+	p.off += int64(len(data))
+	return h, nil
+}
+
+// dataBlock reads the data block described by h into f.V1, or f.V2 if timeSize is 8.
+func (p *blockReader) dataBlock(o Options, name string, h Header, timeFn func([]byte) ([]byte, int64, error), timeSize uint64, f *File) error {
+	b := &f.V1
+	if timeSize == 8 {
+		b = f.V2
+	}
+	size := dataBlockSize(h, timeSize)
+	data, err := p.read(o, size, name+" data block")
+	if err != nil {
+		return err
+	}
+	if err := checkByteOrder(data, h, timeSize); err != nil {
+		return &ParseError{Offset: p.off, Err: err}
+	}
+	if o.BestEffort && timeSize == 4 && h.Version > 1 {
+		// The v2+ header within the v1 data block means the v1 header counts are wrong.
+		// The v1 data block ends there, continue with the v2+ header.
+		if i := bytes.Index(data, []byte(Magic+string(magicVersion(h.Version)))); i >= 0 {
+			p.r = io.MultiReader(bytes.NewReader(data[i:]), p.r)
+			p.end = -1
+			data = data[:i]
+		}
+	}
+	if o.BestEffort && uint64(len(data)) < size {
+		f.Unreadable += size - uint64(len(data))
+	}
+	if !o.BestEffort {
+		// Best-effort mode decodes what it can of a truncated data block instead.
+		if err := checkFit(data, h, timeSize); err != nil {
+			err.Offset += p.off
+			return err
+		}
+	}
+	rest, err := o.parseDataBlock(data, h, timeFn, timeSize, b)
+	if err != nil {
+		return p.parsed(data, rest, err)
+	}
+	p.off += int64(len(data))
+	return nil
+}
+
+// CheckHeaders reports an error naming isutcnt or isstdcnt if the v1 and v2+ headers of a fat file
+// disagree on whether the indicators are present. The other counts may differ: zic omits local time types
+// only used outside the 32-bit range from the v1 data block, and the transitions and leap seconds differ
+// by nature. The indicators are written for both data blocks or for neither, as they describe the same types.
+// Slim files are not checked, their v1 data block is only a placeholder.
+func (f *File) CheckHeaders() error {
+	if f.V2 == nil || Classify(f.V1.Header, &f.V2.Header) == FormatSlim {
+		return nil
+	}
+	h1, h2 := f.V1.Header, f.V2.Header
+	var diffs []string
+	for _, c := range []struct {
+		name   string
+		v1, v2 uint32
+	}{
+		{"isutcnt", h1.IsUTCnt, h2.IsUTCnt},
+		{"isstdcnt", h1.IsStdCnt, h2.IsStdCnt},
+	} {
+		if (c.v1 == 0) != (c.v2 == 0) {
+			diffs = append(diffs, fmt.Sprintf("%s %d != %d", c.name, c.v1, c.v2))
+		}
+	}
+	if len(diffs) > 0 {
+		return fmt.Errorf("v1 and v2+ headers differ: %s", strings.Join(diffs, ", "))
+	}
+	return nil
+}
+
+// Zone is the local time in effect at an instant.
+type Zone struct {
+	// Name is the time zone designation.
+	Name string
+	// UTOff is the number of seconds to be added to UT to get local time.
+	UTOff int32
+	DST   bool
+	// FromFooter is true if the zone was determined by the TZ string in the footer
+	// rather than by the transition table.
+	FromFooter bool
+}
+
+// Lookup returns the local time in effect at Unix time t. The most precise data block is consulted,
+// times after its last transition use the TZ string from the footer if there is one.
+func (f *File) Lookup(t int64) (Zone, error) {
+	b := &f.V1
+	if f.V2 != nil {
+		b = f.V2
+	}
+	n := len(b.TransitionTimes)
+	if f.V2 != nil && (n == 0 || t >= b.TransitionTimes[n-1]) {
+		tz, err := ParseFooter(f.Footer)
+		if err != nil {
+			return Zone{}, err
+		}
+		if tz != nil {
+			z := tz.Lookup(t)
+			z.FromFooter = true
+			return z, nil
+		}
+	}
+	return b.Lookup(t)
+}
+
+// Lookup returns the local time in effect at Unix time t according to the transition table,
+// the last transition's type applies after it. Local time type 0 applies before the first transition.
+func (b *DataBlock) Lookup(t int64) (Zone, error) {
+	n := len(b.TransitionTimes)
+	var typ uint8
+	if i := sort.Search(n, func(i int) bool { return b.TransitionTimes[i] > t }); i > 0 {
+		if i > len(b.TransitionTypes) {
+			return Zone{}, fmt.Errorf("missing transition type %d", i-1)
+		}
+		typ = b.TransitionTypes[i-1]
+	}
+	if int(typ) >= len(b.LocalTimeTypes) {
+		return Zone{}, fmt.Errorf("local time type %d out of range", typ)
+	}
+	lt := b.LocalTimeTypes[typ]
+	name, err := b.Designation(lt.Idx)
+	if err != nil {
+		return Zone{}, err
+	}
+	return Zone{Name: name, UTOff: lt.UTOff, DST: lt.DST != 0}, nil
+}
+
+// CheckV1 reports an error if the v1 data block of a fat file resolves a different local time than the
+// v2+ data block at any transition representable as a 32-bit time. Slim and version 1 files are not checked.
+func (f *File) CheckV1() error {
+	if f.V2 == nil || Classify(f.V1.Header, &f.V2.Header) != FormatFat {
+		return nil
+	}
+	times := append([]int64{}, f.V1.TransitionTimes...)
+	for _, t := range f.V2.TransitionTimes {
+		if t >= math.MinInt32 && t <= math.MaxInt32 {
+			times = append(times, t)
+		}
+	}
+	for _, t := range times {
+		z1, err := f.V1.Lookup(t)
+		if err != nil {
+			return err
+		}
+		z2, err := f.Lookup(t)
+		if err != nil {
+			return err
+		}
+		z2.FromFooter = false
+		if z1 != z2 {
+			return fmt.Errorf("at %d the v1 data block resolves %s utoff=%d dst=%t, the v2+ data block %s utoff=%d dst=%t",
+				t, z1.Name, z1.UTOff, z1.DST, z2.Name, z2.UTOff, z2.DST)
+		}
+	}
+	return nil
+}
+
+// Format is the layout of a TZif file.
+type Format string
+
+const (
+	// FormatV1Only is a version 1 file, it has no v2+ data block and no footer.
+	FormatV1Only Format = "v1-only"
+	// FormatSlim has a v1 data block without transitions, all data is in the v2+ data block and the footer.
+	// zic -b slim writes such files.
+	FormatSlim Format = "slim"
+	// FormatFat duplicates the data that fits 32-bit times in the v1 data block, as zic -b fat does.
+	FormatFat Format = "fat"
+)
+
+// Classify returns the format of a file with the given v1 header and v2+ header, v2 is nil for version 1 files.
+func Classify(v1 Header, v2 *Header) Format {
+	if v2 == nil {
+		return FormatV1Only
+	}
+	if v1.TimeCnt == 0 && v1.LeapCnt == 0 && (v2.TimeCnt > 0 || v2.LeapCnt > 0 || v1.CharCnt < v2.CharCnt) {
+		return FormatSlim
+	}
+	return FormatFat
+}
+
+// ReadVersion reads no more than the magic and version bytes from r and returns the version.
+func ReadVersion(r io.Reader) (uint8, error) {
+	buf := make([]byte, len(Magic)+1)
+	_, err := io.ReadFull(r, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return 0, fmt.Errorf("not a tzif file")
+	}
+	if err != nil {
+		return 0, err
+	}
+	if string(buf[:len(Magic)]) != Magic {
+		return 0, fmt.Errorf("not a tzif file")
+	}
+	return parseVersion(buf[len(Magic)])
+}
+
+func time32(data []byte) ([]byte, int64, error) {
+	if len(data) < 4 {
+		return data, 0, fmt.Errorf("missing time32 data")
+	}
+	value := int32(binary.BigEndian.Uint32(data[0:4]))
+	return data[4:], int64(value), nil
+}
+
+func time64(data []byte) ([]byte, int64, error) {
+	if len(data) < 8 {
+		return data, 0, fmt.Errorf("missing time64 data")
+	}
+	value := int64(binary.BigEndian.Uint64(data[0:8]))
+	return data[8:], value, nil
+}
+
+// magicVersion returns the version byte following the magic for version, the inverse of parseVersion.
+func magicVersion(version uint8) byte {
+	if version == 1 {
+		return 0
+	}
+	return '0' + version
+}
+
+func parseVersion(b byte) (uint8, error) {
+	switch b {
+	case 0:
+		return 1, nil
+	case 0x32:
+		return 2, nil
+	case 0x33:
+		return 3, nil
+	case 0x34:
+		return 4, nil
+	default:
+		return 0, fmt.Errorf("unsupported version: %d", b)
+	}
+}
+
+func (o Options) parseHeader(data []byte) ([]byte, Header, error) {
+	var h Header
+	// magic
+	if len(data) < 4 || !bytes.Equal(data[0:4], []byte(Magic)) {
+		return data, h, &ParseError{Field: "magic", Expected: strconv.Quote(Magic), Actual: strconv.Quote(string(data[:min(4, len(data))])),
+			Err: fmt.Errorf("not a tzif file")}
+	}
+	data = data[4:]
+	// version
+	if len(data) < 1 {
+		return data, h, fmt.Errorf("missing version")
+	}
+	var err error
+	h.Version, err = parseVersion(data[0])
+	if err != nil {
+		return data, h, &ParseError{Offset: int64(len(Magic)), Field: "version", Expected: "0x00, 0x32, 0x33 or 0x34",
+			Actual: fmt.Sprintf("0x%02x", data[0]), Err: fmt.Errorf("unsupported value 0x%02x", data[0])}
+	}
+	data = data[1:]
+	// unused
+	if len(data) < 15 {
+		return data, h, fmt.Errorf("missing unused")
+	}
+	unused := data[:15]
+	data = data[15:]
+	// isutcnt
+	if len(data) < 4 {
+		return data, h, fmt.Errorf("missing isutcnt")
+	}
+	h.IsUTCnt = binary.BigEndian.Uint32(data[:4])
+	data = data[4:]
+	// isstdcnt
+	if len(data) < 4 {
+		return data, h, fmt.Errorf("missing isstdcnt")
+	}
+	h.IsStdCnt = binary.BigEndian.Uint32(data[:4])
+	data = data[4:]
+	// leapcnt
+	if len(data) < 4 {
+		return data, h, fmt.Errorf("missing leapcnt")
+	}
+	h.LeapCnt = binary.BigEndian.Uint32(data[:4])
+	data = data[4:]
+	// timecnt
+	if len(data) < 4 {
+		return data, h, fmt.Errorf("missing timecnt")
+	}
+	h.TimeCnt = binary.BigEndian.Uint32(data[:4])
+	data = data[4:]
+	// typecnt
+	if len(data) < 4 {
+		return data, h, fmt.Errorf("missing typecnt")
+	}
+	h.TypeCnt = binary.BigEndian.Uint32(data[:4])
+	data = data[4:]
+	// charcnt
+	if len(data) < 4 {
+		return data, h, fmt.Errorf("missing charcnt")
+	}
+	h.CharCnt = binary.BigEndian.Uint32(data[:4])
+	data = data[4:]
+	if o.Strict {
+		if err := checkHeader(h, unused); err != nil {
+			return data, h, err
+		}
+	}
+	return data, h, nil
+}
+
+// checkHeader checks the requirements on header fields enforced in strict mode.
+// The offset of the error is relative to the start of the header.
+func checkHeader(h Header, unused []byte) *ParseError {
+	for i, b := range unused {
+		if b != 0 {
+			return &ParseError{Offset: int64(len(Magic) + 1 + i), Field: "unused", Expected: "0x00", Actual: fmt.Sprintf("0x%02x", b),
+				Err: fmt.Errorf("byte %d is 0x%02x, must be zero", i, b)}
+		}
+	}
+	for i, cnt := range []uint32{h.TypeCnt, h.CharCnt} {
+		if cnt == 0 {
+			return &ParseError{Offset: countsOffset + 4*int64(4+i), Field: countNames[4+i], Expected: "at least 1", Actual: "0",
+				Err: fmt.Errorf("0, must be at least 1")}
+		}
+	}
+	return nil
+}
+
+func (o Options) parseDataBlock(data []byte, h Header, timeFn func([]byte) ([]byte, int64, error), timeSize uint64, b *DataBlock) ([]byte, error) {
+	b.Header = h
+	// The sections are allocated upfront, but no larger than data can hold, so a corrupt count
+	// in a truncated file parsed in best-effort mode does not allocate more than the input holds.
+	data, err := o.parseSection(data, uint64(h.TimeCnt)*timeSize, SectionTransitionTimes, b, func(data []byte) ([]byte, error) {
+		b.TransitionTimes = make([]int64, 0, min(uint64(h.TimeCnt), uint64(len(data))/timeSize))
+		for i := uint32(0); i < h.TimeCnt; i++ {
+			var ts int64
+			var err error
+			data, ts, err = timeFn(data)
+			if err != nil {
+				return data, err
+			}
+			b.TransitionTimes = append(b.TransitionTimes, ts)
+		}
+		if o.Strict {
+			return data, b.CheckTransitionOrder()
+		}
+		return data, nil
+	})
+	if err != nil {
+		return data, err
+	}
+	data, err = o.parseSection(data, uint64(h.TimeCnt), SectionTransitionTypes, b, func(data []byte) ([]byte, error) {
+		b.TransitionTypes = make([]uint8, 0, min(uint64(h.TimeCnt), uint64(len(data))))
+		for i := uint32(0); i < h.TimeCnt; i++ {
+			if len(data) < 1 {
+				return data, fmt.Errorf("missing transition type")
+			}
+			tt := data[0]
+			if uint32(tt) >= h.TypeCnt {
+				if err := o.tolerate(b, fmt.Errorf("transition type %d of transition %d out of range", tt, i)); err != nil {
+					return data, err
+				}
+			}
+			data = data[1:]
+			b.TransitionTypes = append(b.TransitionTypes, tt)
+		}
+		return data, nil
+	})
+	if err != nil {
+		return data, err
+	}
+	data, err = o.parseSection(data, uint64(h.TypeCnt)*6, SectionLocalTimeTypes, b, func(data []byte) ([]byte, error) {
+		b.LocalTimeTypes = make([]LocalTimeType, 0, min(uint64(h.TypeCnt), uint64(len(data))/6))
+		for i := uint32(0); i < h.TypeCnt; i++ {
+			if len(data) < 6 {
+				return data, fmt.Errorf("missing type record")
+			}
+			t := LocalTimeType{
+				UTOff: int32(binary.BigEndian.Uint32(data[0:4])),
+				DST:   data[4],
+				Idx:   data[5],
+			}
+			var err error
+			if h.CharCnt == 0 {
+				err = fmt.Errorf("idx %d out of range, charcnt is 0", t.Idx)
+			} else if uint32(t.Idx) >= h.CharCnt {
+				err = fmt.Errorf("idx %d out of range (0..%d)", t.Idx, h.CharCnt-1)
+			}
+			if err != nil {
+				if err = o.tolerate(b, err); err != nil {
+					return data, err
+				}
+			}
+			data = data[6:]
+			b.LocalTimeTypes = append(b.LocalTimeTypes, t)
+		}
+		return data, nil
+	})
+	if err != nil {
+		return data, err
+	}
+	data, err = o.parseSection(data, uint64(h.CharCnt), SectionDesignations, b, func(data []byte) ([]byte, error) {
+		if uint32(len(data)) < h.CharCnt {
+			return data, fmt.Errorf("missing time zone designations")
+		}
+		b.Designations = data[:h.CharCnt]
+		data = data[h.CharCnt:]
+		if len(b.Designations) > 0 && b.Designations[len(b.Designations)-1] != 0 {
+			return data, o.tolerate(b, fmt.Errorf("extra data at end of tz desig"))
+		}
+		return data, nil
+	})
+	if err != nil {
+		return data, err
+	}
+	data, err = o.parseSection(data, uint64(h.LeapCnt)*(timeSize+4), SectionLeapSeconds, b, func(data []byte) ([]byte, error) {
+		if o.SkipLeapSeconds {
+			size := uint64(h.LeapCnt) * (timeSize + 4)
+			if uint64(len(data)) < size {
+				return data, fmt.Errorf("leap second records are truncated")
+			}
+			return data[size:], nil
+		}
+		b.LeapSeconds = make([]LeapSecond, 0, min(uint64(h.LeapCnt), uint64(len(data))/(timeSize+4)))
+		for i := uint32(0); i < h.LeapCnt; i++ {
+			var ls LeapSecond
+			var err error
+			data, ls.Occur, err = timeFn(data)
+			if err != nil {
+				return data, fmt.Errorf("leap second record %d of %d is truncated: %v", i, h.LeapCnt, err)
+			}
+			if len(data) < 4 {
+				return data, fmt.Errorf("leap second record %d of %d is truncated: missing corr", i, h.LeapCnt)
+			}
+			ls.Corr = int32(binary.BigEndian.Uint32(data[0:4]))
+			data = data[4:]
+			b.LeapSeconds = append(b.LeapSeconds, ls)
+		}
+		if o.Strict {
+			return data, b.CheckLeapSeconds()
+		}
+		return data, nil
+	})
+	if err != nil {
+		return data, err
+	}
+	data, err = o.parseSection(data, uint64(h.IsStdCnt), SectionIsStd, b, func(data []byte) ([]byte, error) {
+		if h.IsStdCnt != 0 && h.IsStdCnt != h.TypeCnt {
+			err := fmt.Errorf("isstdcnt is %d, must be zero or equal to typecnt (%d)", h.IsStdCnt, h.TypeCnt)
+			if err = o.tolerate(b, err); err != nil {
+				return data, err
+			}
+		}
+		for i := uint32(0); i < h.IsStdCnt; i++ {
+			if len(data) < 1 {
+				return data, fmt.Errorf("missing std/wall indicator")
+			}
+			if data[0] > 1 {
+				if err := o.tolerate(b, fmt.Errorf("unsupported std/wall indicator: %d", data[0])); err != nil {
+					return data, err
+				}
+			}
+			b.IsStd = append(b.IsStd, data[0] != 0)
+			data = data[1:]
+		}
+		return data, nil
+	})
+	if err != nil {
+		return data, err
+	}
+	return o.parseSection(data, uint64(h.IsUTCnt), SectionIsUT, b, func(data []byte) ([]byte, error) {
+		if h.IsUTCnt != 0 && h.IsUTCnt != h.TypeCnt {
+			err := fmt.Errorf("isutcnt is %d, must be zero or equal to typecnt (%d)", h.IsUTCnt, h.TypeCnt)
+			if err = o.tolerate(b, err); err != nil {
+				return data, err
+			}
+		}
+		for i := uint32(0); i < h.IsUTCnt; i++ {
+			if len(data) < 1 {
+				return data, fmt.Errorf("missing ut/local indicator")
+			}
+			if data[0] > 1 {
+				if err := o.tolerate(b, fmt.Errorf("unsupported UT/local indicator: %d", data[0])); err != nil {
+					return data, err
+				}
+			}
+			isUT := data[0] != 0
+			if isUT && (int(i) >= len(b.IsStd) || !b.IsStd[i]) {
+				err := fmt.Errorf("local time type %d has UT indicator set but is not standard time", i)
+				if err = o.tolerate(b, err); err != nil {
+					return data, err
+				}
+			}
+			b.IsUT = append(b.IsUT, isUT)
+			data = data[1:]
+		}
+		return data, nil
+	})
+}
+
+// tolerate records err in b.Warnings and returns nil in lenient mode, otherwise it returns err.
+func (o Options) tolerate(b *DataBlock, err error) error {
+	if !o.Lenient {
+		return err
+	}
+	b.Warnings = append(b.Warnings, err)
+	return nil
+}
+
+// parseSection calls parseFn to parse a data block section that occupies size bytes at the start of data.
+// In best-effort mode, an error is recorded in b.Errors instead of returned and parsing resumes after
+// the section, as long as data holds the whole section so that the next section can still be found.
+func (o Options) parseSection(data []byte, size uint64, section Section, b *DataBlock, parseFn func([]byte) ([]byte, error)) ([]byte, error) {
+	rest, err := parseFn(data)
+	if err == nil || !o.BestEffort {
+		return rest, err
+	}
+	if b.Errors == nil {
+		b.Errors = make(map[Section]error)
+	}
+	if uint64(len(data)) < size {
+		// The data block is truncated, the following sections are missing as well.
+		if len(data) == 0 {
+			err = fmt.Errorf("missing, the data block ends before it")
+		}
+		b.Errors[section] = err
+		return data[len(data):], nil
+	}
+	b.Errors[section] = err
+	return data[size:], nil
+}
+
+// checkByteOrder reports an error if the counts in h do not fit into data, but would fit if they were
+// stored little-endian. TZif is always big-endian, so such a file is corrupt or was written incorrectly.
+func checkByteOrder(data []byte, h Header, timeSize uint64) error {
+	if dataBlockSize(h, timeSize) <= uint64(len(data)) {
+		return nil
+	}
+	swapped := h
+	for _, cnt := range []*uint32{&swapped.IsUTCnt, &swapped.IsStdCnt, &swapped.LeapCnt, &swapped.TimeCnt, &swapped.TypeCnt, &swapped.CharCnt} {
+		*cnt = bits.ReverseBytes32(*cnt)
+	}
+	if dataBlockSize(swapped, timeSize) > uint64(len(data)) {
+		return nil
+	}
+	return fmt.Errorf("header counts need %d bytes but only %d remain; byte-swapped they would fit "+
+		"(isutcnt=%d isstdcnt=%d leapcnt=%d timecnt=%d typecnt=%d charcnt=%d), the file may be little-endian or corrupt",
+		dataBlockSize(h, timeSize), len(data),
+		swapped.IsUTCnt, swapped.IsStdCnt, swapped.LeapCnt, swapped.TimeCnt, swapped.TypeCnt, swapped.CharCnt)
+}
+
+// checkFit reports a ParseError naming the count of the first section of the data block described by h
+// that does not fit in data. The offset of the error is relative to the start of data.
+func checkFit(data []byte, h Header, timeSize uint64) *ParseError {
+	sections := []struct {
+		field string
+		count uint32
+		size  uint64
+	}{
+		{"timecnt", h.TimeCnt, timeSize},
+		{"timecnt", h.TimeCnt, 1},
+		{"typecnt", h.TypeCnt, 6},
+		{"charcnt", h.CharCnt, 1},
+		{"leapcnt", h.LeapCnt, timeSize + 4},
+		{"isstdcnt", h.IsStdCnt, 1},
+		{"isutcnt", h.IsUTCnt, 1},
+	}
+	var start uint64
+	for _, s := range sections {
+		size := uint64(s.count) * s.size
+		if start+size > uint64(len(data)) {
+			return &ParseError{Offset: int64(start), Field: s.field,
+				Expected: fmt.Sprintf("%d bytes", size), Actual: fmt.Sprintf("%d bytes", uint64(len(data))-start),
+				Err: fmt.Errorf("%d entries need %d bytes but only %d remain", s.count, size, uint64(len(data))-start)}
+		}
+		start += size
+	}
+	return nil
+}
+
+// dataBlockSize returns the length in bytes of the data block described by h,
+// with timeSize being 4 for the version 1 data block and 8 for the version 2+ data block.
+func dataBlockSize(h Header, timeSize uint64) uint64 {
+	return uint64(h.TimeCnt)*(timeSize+1) +
+		uint64(h.TypeCnt)*6 +
+		uint64(h.CharCnt) +
+		uint64(h.LeapCnt)*(timeSize+4) +
+		uint64(h.IsStdCnt) +
+		uint64(h.IsUTCnt)
+}
@@ -0,0 +1,280 @@
+package tzif
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// parseError parses data with o and returns the error, failing the test if parsing succeeds
+// or the error does not contain want.
+func parseError(t *testing.T, o Options, data []byte, want string) error {
+	t.Helper()
+	_, err := o.Parse(bytes.NewReader(data))
+	if err == nil {
+		t.Fatalf("parsed without error, want %q", want)
+	}
+	if !strings.Contains(err.Error(), want) {
+		t.Fatalf("error %q, want %q", err, want)
+	}
+	return err
+}
+
+func TestTransitionTypeEqualToTypeCnt(t *testing.T) {
+	b := testZone()
+	// Valid indexes are 0..typecnt-1.
+	b.types = []uint8{1, 2}
+	data := testFile{version: 1, v1: b}.bytes()
+	err := parseError(t, Options{}, data, "transition type 2 of transition 1 out of range")
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("error %T, want *ParseError", err)
+	}
+	f, err := Options{Lenient: true}.Parse(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.V1.Warnings) != 1 {
+		t.Errorf("warnings %v, want the transition type", f.V1.Warnings)
+	}
+}
+
+func TestCharCntZero(t *testing.T) {
+	b := testZone()
+	b.records = b.records[:1]
+	b.types = []uint8{0, 0}
+	b.isStd, b.isUT = nil, nil
+	b.desigs = ""
+	data := testFile{version: 1, v1: b}.bytes()
+	parseError(t, Options{}, data, "idx 0 out of range, charcnt is 0")
+	err := parseError(t, Options{Strict: true}, data, "charcnt: 0, must be at least 1")
+	var pe *ParseError
+	if !errors.As(err, &pe) || pe.Offset != countsOffset+20 {
+		t.Errorf("error %#v, want the offset of charcnt", err)
+	}
+}
+
+func TestCheckHeaders(t *testing.T) {
+	// zic omits types only used outside the 32-bit range from the v1 data block.
+	fewerTypes := testZone()
+	fewerTypes.times, fewerTypes.types = fewerTypes.times[:1], fewerTypes.types[:1]
+	fewerTypes.records = append(fewerTypes.records, LocalTimeType{UTOff: 3600 + 1800, Idx: 0})
+	fewerTypes.isStd, fewerTypes.isUT = append(fewerTypes.isStd, 0), append(fewerTypes.isUT, 0)
+	noIndicators := testZone()
+	noIndicators.isStd, noIndicators.isUT = nil, nil
+	for _, tc := range []struct {
+		name string
+		v2   testBlock
+		want string
+	}{
+		{"same", testZone(), ""},
+		{"fewer types in v1", fewerTypes, ""},
+		{"indicators only in v1", noIndicators, "isutcnt 2 != 0, isstdcnt 2 != 0"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data := testFile{version: 2, v1: testZone(), v2: &tc.v2, footer: "\n\n"}.bytes()
+			f, err := Parse(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = f.CheckHeaders()
+			if tc.want == "" && err != nil {
+				t.Errorf("error %q, want none", err)
+			}
+			if tc.want != "" && (err == nil || !strings.Contains(err.Error(), tc.want)) {
+				t.Errorf("error %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestClassify(t *testing.T) {
+	placeholder := Header{Version: 2, TypeCnt: 1, CharCnt: 1}
+	for _, tc := range []struct {
+		name string
+		v1   Header
+		v2   *Header
+		want Format
+	}{
+		{"version 1", Header{Version: 1, TimeCnt: 5, TypeCnt: 2, CharCnt: 8}, nil, FormatV1Only},
+		{"slim", placeholder, &Header{Version: 2, TimeCnt: 61, TypeCnt: 4, CharCnt: 17}, FormatSlim},
+		{"slim leap seconds only", placeholder, &Header{Version: 4, LeapCnt: 27, TypeCnt: 1, CharCnt: 4}, FormatSlim},
+		{"slim fixed offset", placeholder, &Header{Version: 2, TypeCnt: 1, CharCnt: 4}, FormatSlim},
+		{"fat", Header{Version: 2, TimeCnt: 143, TypeCnt: 8, CharCnt: 17}, &Header{Version: 2, TimeCnt: 144, TypeCnt: 9, CharCnt: 21}, FormatFat},
+		{"fat fixed offset", Header{Version: 2, TypeCnt: 1, CharCnt: 4}, &Header{Version: 2, TypeCnt: 1, CharCnt: 4}, FormatFat},
+	} {
+		if got := Classify(tc.v1, tc.v2); got != tc.want {
+			t.Errorf("%s: %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestIndicators(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		isStd, isUT []uint8
+		want        []string
+	}{
+		{"isstdcnt", []uint8{1}, nil, []string{"isstdcnt is 1", "typecnt (2)"}},
+		{"isutcnt", []uint8{1, 0}, []uint8{1, 0, 0}, []string{"isutcnt is 3", "typecnt (2)"}},
+		{"UT without standard", []uint8{0, 0}, []uint8{1, 0}, []string{"local time type 0 has UT indicator set but is not standard time"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := testZone()
+			b.isStd, b.isUT = tc.isStd, tc.isUT
+			data := testFile{version: 1, v1: b}.bytes()
+			for _, want := range tc.want {
+				parseError(t, Options{}, data, want)
+			}
+		})
+	}
+	b := testZone()
+	b.isStd, b.isUT = nil, nil
+	if _, err := Parse(bytes.NewReader(testFile{version: 1, v1: b}.bytes())); err != nil {
+		t.Errorf("without indicators: %v", err)
+	}
+}
+
+func TestDesignationAt(t *testing.T) {
+	b := testZone()
+	b.records = append(b.records, LocalTimeType{UTOff: -36000, Idx: 10})
+	b.isStd, b.isUT = nil, nil
+	b.desigs = "CET\x00CEST\x00AHST\x00LMT"
+	v1 := testPlaceholder
+	v1.desigs = "UTC\x00"
+	f, err := Options{Lenient: true}.Parse(bytes.NewReader(testFile{version: 2, v1: v1, v2: &b, footer: "\n\n"}.bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		idx  byte
+		want string
+		err  string
+	}{
+		{0, "CET", ""},
+		{4, "CEST", ""},
+		{3, "", ""},
+		{9, "AHST", ""},
+		// zic shares the suffix of a designation.
+		{10, "HST", ""},
+		{12, "T", ""},
+		{14, "", "designation at idx 14 is not NUL-terminated"},
+		{17, "", "idx 17 out of range (0..16)"},
+		{255, "", "idx 255 out of range (0..16)"},
+	} {
+		got, err := f.DesignationAt(tc.idx)
+		if tc.err != "" {
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("idx %d: error %v, want %q", tc.idx, err, tc.err)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Errorf("idx %d: %q, %v, want %q", tc.idx, got, err, tc.want)
+		}
+	}
+	// Version 1 files use the v1 data block.
+	f.V2 = nil
+	if got, err := f.DesignationAt(0); err != nil || got != "UTC" {
+		t.Errorf("version 1: %q, %v, want \"UTC\"", got, err)
+	}
+}
+
+func BenchmarkParse100kTransitions(b *testing.B) {
+	const n = 100000
+	block := testZone()
+	block.times, block.types = make([]int64, n), make([]uint8, n)
+	for i := range block.times {
+		block.times[i] = int64(i) * 3600
+		block.types[i] = uint8(i % 2)
+	}
+	data := testSlim(block).bytes()
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		f, err := Parse(bytes.NewReader(data))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(f.V2.TransitionTimes) != n {
+			b.Fatalf("%d transitions, want %d", len(f.V2.TransitionTimes), n)
+		}
+	}
+}
+
+func TestSkipLeapSeconds(t *testing.T) {
+	v1 := testZone()
+	v1.leaps = []LeapSecond{{Occur: 78796800, Corr: 1}, {Occur: 94694401, Corr: 2}}
+	v2 := v1
+	files := map[string][]byte{
+		"built v1":   testFile{version: 1, v1: v1}.bytes(),
+		"built fat":  testFile{version: 2, v1: v1, v2: &v2, footer: "\nCET-1\n"}.bytes(),
+		"right-utc":  readTestdata(t, "right-utc.tzif"),
+		"no records": readTestdata(t, "prague-fat.tzif"),
+	}
+	for name, data := range files {
+		t.Run(name, func(t *testing.T) {
+			want, err := Parse(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := Options{SkipLeapSeconds: true, Strict: true}.Parse(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, b := range []*DataBlock{&got.V1, got.V2} {
+				if b != nil && len(b.LeapSeconds) != 0 {
+					t.Errorf("%d leap seconds, want none", len(b.LeapSeconds))
+				}
+			}
+			// The sections following the leap second records and the footer are read from the same offsets.
+			want.V1.LeapSeconds = nil
+			if want.V2 != nil {
+				want.V2.LeapSeconds = nil
+			}
+			if !got.Equal(want) || !bytes.Equal(got.Footer, want.Footer) {
+				t.Errorf("parsed differently without leap seconds")
+			}
+			if want.V2 == nil && len(got.V1.IsUT) != 2 {
+				t.Errorf("UT/local indicators %v", got.V1.IsUT)
+			}
+		})
+	}
+	data := files["built v1"]
+	_, err := Options{SkipLeapSeconds: true}.Parse(bytes.NewReader(data[:len(data)-8]))
+	if err == nil {
+		t.Errorf("truncated file parsed without error")
+	}
+}
+
+func TestParseBytes(t *testing.T) {
+	data := readTestdata(t, "prague-fat.tzif")
+	f, err := ParseBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.Equal(want) || len(f.V2.TransitionTimes) != 144 {
+		t.Errorf("ParseBytes differs from Parse")
+	}
+	if _, err := ParseBytes(data[:100]); err == nil {
+		t.Errorf("truncated file parsed without error")
+	}
+}
+
+func TestFooterOffset(t *testing.T) {
+	for _, name := range []string{"prague-fat.tzif", "prague-slim.tzif", "right-utc.tzif"} {
+		data := readTestdata(t, name)
+		f, err := ParseBytes(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := f.FooterOffset(), int64(len(data)-len(f.Footer)); got != want {
+			t.Errorf("%s: footer offset %d, want %d", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,414 @@
+package tzif
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TZString is a parsed POSIX TZ string as found in the footer of version 2+ files,
+// for example "CET-1CEST,M3.5.0,M10.5.0/3".
+type TZString struct {
+	// Std is the designation of standard time.
+	Std string
+	// StdOff is the number of seconds to be added to UT to get standard time.
+	// Note that POSIX offsets have the opposite sign, so "CET-1" has StdOff 3600.
+	StdOff int32
+	// DST is the designation of daylight saving time, empty if the zone does not observe DST.
+	DST string
+	// DSTOff is the number of seconds to be added to UT to get daylight saving time.
+	// It defaults to one hour more than StdOff.
+	DSTOff int32
+	// Start and End are the rules for the transitions to and from DST.
+	// They are nil if DST is empty or the TZ string does not specify the rules.
+	Start, End *Rule
+}
+
+// RuleKind is the form of a transition rule.
+type RuleKind int
+
+const (
+	// RuleJulian is the Jn form, a day 1..365 of the year with February 29 never counted.
+	RuleJulian RuleKind = iota
+	// RuleZeroJulian is the n form, a day 0..365 of the year with February 29 counted in leap years.
+	RuleZeroJulian
+	// RuleMonthWeekDay is the Mm.w.d form, day d (0 is Sunday) of week w (5 is the last week) of month m.
+	RuleMonthWeekDay
+)
+
+// Rule specifies when a transition to or from DST happens each year.
+type Rule struct {
+	Kind RuleKind
+	// Day is the day of the year for RuleJulian and RuleZeroJulian.
+	Day int
+	// Month, Week and Weekday specify the day for RuleMonthWeekDay.
+	Month, Week, Weekday int
+	// Time is the local time of the transition in seconds since midnight.
+	// It defaults to 7200 and may be negative or exceed a day as permitted by version 3 files.
+	Time int32
+}
+
+// ParseFooter parses the TZ string enclosed in newlines in the footer of a version 2+ file.
+// It returns nil if the TZ string is empty.
+func ParseFooter(footer []byte) (*TZString, error) {
+	if len(footer) < 2 || footer[0] != '\n' || footer[len(footer)-1] != '\n' {
+		return nil, fmt.Errorf("footer is not enclosed in newlines")
+	}
+	s := string(footer[1 : len(footer)-1])
+	if s == "" {
+		return nil, nil
+	}
+	tz, err := ParseTZString(s)
+	if err != nil {
+		return nil, err
+	}
+	return &tz, nil
+}
+
+// ParseTZString parses a POSIX TZ string including the extensions of RFC 8536 section 3.3.1.
+func ParseTZString(s string) (TZString, error) {
+	p := tzParser{s: s}
+	tz, err := p.parse()
+	if err != nil {
+		return tz, fmt.Errorf("invalid TZ string %q at offset %d: %v", s, p.pos, err)
+	}
+	return tz, nil
+}
+
+type tzParser struct {
+	s   string
+	pos int
+}
+
+func (p *tzParser) parse() (TZString, error) {
+	var tz TZString
+	var err error
+	tz.Std, err = p.name()
+	if err != nil {
+		return tz, err
+	}
+	off, err := p.offset(24)
+	if err != nil {
+		return tz, err
+	}
+	tz.StdOff = -off
+	if p.done() {
+		return tz, nil
+	}
+	tz.DST, err = p.name()
+	if err != nil {
+		return tz, err
+	}
+	tz.DSTOff = tz.StdOff + 3600
+	if !p.done() && p.peek() != ',' {
+		off, err = p.offset(24)
+		if err != nil {
+			return tz, err
+		}
+		tz.DSTOff = -off
+	}
+	if p.done() {
+		return tz, nil
+	}
+	if err = p.expect(','); err != nil {
+		return tz, err
+	}
+	tz.Start, err = p.rule()
+	if err != nil {
+		return tz, err
+	}
+	if err = p.expect(','); err != nil {
+		return tz, err
+	}
+	tz.End, err = p.rule()
+	if err != nil {
+		return tz, err
+	}
+	if !p.done() {
+		return tz, fmt.Errorf("unexpected %q", p.s[p.pos:])
+	}
+	return tz, nil
+}
+
+func (p *tzParser) done() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *tzParser) peek() byte {
+	return p.s[p.pos]
+}
+
+func (p *tzParser) expect(c byte) error {
+	if p.done() || p.peek() != c {
+		return fmt.Errorf("expected %q", c)
+	}
+	p.pos++
+	return nil
+}
+
+// name parses a designation, either alphabetic or quoted in angle brackets.
+func (p *tzParser) name() (string, error) {
+	if !p.done() && p.peek() == '<' {
+		end := strings.IndexByte(p.s[p.pos:], '>')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quoted designation")
+		}
+		name := p.s[p.pos+1 : p.pos+end]
+		for i := 0; i < len(name); i++ {
+			if !isAlpha(name[i]) && !isDigit(name[i]) && name[i] != '+' && name[i] != '-' {
+				return "", fmt.Errorf("invalid character %q in quoted designation", name[i])
+			}
+		}
+		if len(name) < 3 {
+			return "", fmt.Errorf("designation %q shorter than 3 characters", name)
+		}
+		p.pos += end + 1
+		return name, nil
+	}
+	start := p.pos
+	for !p.done() && isAlpha(p.peek()) {
+		p.pos++
+	}
+	name := p.s[start:p.pos]
+	if len(name) < 3 {
+		return "", fmt.Errorf("designation %q shorter than 3 characters", name)
+	}
+	return name, nil
+}
+
+// offset parses [+|-]hh[:mm[:ss]] with hh at most maxHours and returns the value in seconds.
+func (p *tzParser) offset(maxHours int) (int32, error) {
+	sign := int32(1)
+	if !p.done() && (p.peek() == '+' || p.peek() == '-') {
+		if p.peek() == '-' {
+			sign = -1
+		}
+		p.pos++
+	}
+	hours, err := p.number(0, maxHours)
+	if err != nil {
+		return 0, err
+	}
+	secs := int32(hours) * 3600
+	for _, unit := range []int32{60, 1} {
+		if p.done() || p.peek() != ':' {
+			break
+		}
+		p.pos++
+		n, err := p.number(0, 59)
+		if err != nil {
+			return 0, err
+		}
+		secs += int32(n) * unit
+	}
+	return sign * secs, nil
+}
+
+// rule parses a transition rule with an optional time.
+func (p *tzParser) rule() (*Rule, error) {
+	var r Rule
+	var err error
+	switch {
+	case p.done():
+		return nil, fmt.Errorf("missing rule")
+	case p.peek() == 'J':
+		p.pos++
+		r.Kind = RuleJulian
+		r.Day, err = p.number(1, 365)
+	case p.peek() == 'M':
+		p.pos++
+		r.Kind = RuleMonthWeekDay
+		r.Month, err = p.number(1, 12)
+		if err == nil {
+			err = p.expect('.')
+		}
+		if err == nil {
+			r.Week, err = p.number(1, 5)
+		}
+		if err == nil {
+			err = p.expect('.')
+		}
+		if err == nil {
+			r.Weekday, err = p.number(0, 6)
+		}
+	default:
+		r.Kind = RuleZeroJulian
+		r.Day, err = p.number(0, 365)
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.Time = 7200
+	if !p.done() && p.peek() == '/' {
+		p.pos++
+		r.Time, err = p.offset(167)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &r, nil
+}
+
+// number parses a decimal number between lo and hi.
+func (p *tzParser) number(lo, hi int) (int, error) {
+	start := p.pos
+	n := 0
+	for !p.done() && isDigit(p.peek()) && n <= hi {
+		n = n*10 + int(p.peek()-'0')
+		p.pos++
+	}
+	if p.pos == start {
+		return 0, fmt.Errorf("expected a number")
+	}
+	if n < lo || n > hi {
+		return 0, fmt.Errorf("number %d out of range (%d..%d)", n, lo, hi)
+	}
+	return n, nil
+}
+
+func isAlpha(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// Transitions returns the instants of the transitions to and from DST in the given year.
+// The DST period wraps across the new year if end is before start, as in the southern hemisphere.
+// It returns ok false if tz has no DST rules.
+func (tz *TZString) Transitions(year int) (start, end int64, ok bool) {
+	if tz.DST == "" || tz.Start == nil || tz.End == nil {
+		return 0, 0, false
+	}
+	// The start rule time is in standard time, the end rule time in daylight saving time.
+	start = tz.Start.dayStart(year) + int64(tz.Start.Time) - int64(tz.StdOff)
+	end = tz.End.dayStart(year) + int64(tz.End.Time) - int64(tz.DSTOff)
+	return start, end, true
+}
+
+// Lookup returns the local time in effect at Unix time t according to the TZ string.
+// Zones with a DST designation but no rules are treated as always observing standard time.
+func (tz *TZString) Lookup(t int64) Zone {
+	std := Zone{Name: tz.Std, UTOff: tz.StdOff}
+	// The rules apply to the year of the local standard time.
+	year := time.Unix(t+int64(tz.StdOff), 0).UTC().Year()
+	start, end, ok := tz.Transitions(year)
+	if !ok {
+		return std
+	}
+	var dst bool
+	if start < end {
+		dst = start <= t && t < end
+	} else {
+		dst = t < end || start <= t
+	}
+	if !dst {
+		return std
+	}
+	return Zone{Name: tz.DST, UTOff: tz.DSTOff, DST: true}
+}
+
+// dayStart returns the Unix time of midnight UT of the day the rule selects in year.
+func (r *Rule) dayStart(year int) int64 {
+	var day time.Time
+	switch r.Kind {
+	case RuleJulian:
+		yday := r.Day
+		if isLeap(year) && r.Day >= 60 {
+			yday++
+		}
+		day = time.Date(year, time.January, yday, 0, 0, 0, 0, time.UTC)
+	case RuleZeroJulian:
+		day = time.Date(year, time.January, r.Day+1, 0, 0, 0, 0, time.UTC)
+	default:
+		first := time.Date(year, time.Month(r.Month), 1, 0, 0, 0, 0, time.UTC)
+		mday := 1 + (r.Weekday-int(first.Weekday())+7)%7 + (r.Week-1)*7
+		// Week 5 means the last such weekday of the month.
+		for mday > daysIn(time.Month(r.Month), year) {
+			mday -= 7
+		}
+		day = time.Date(year, time.Month(r.Month), mday, 0, 0, 0, 0, time.UTC)
+	}
+	return day.Unix()
+}
+
+func isLeap(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+func daysIn(m time.Month, year int) int {
+	return time.Date(year, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// String formats tz as a POSIX TZ string, omitting the DST offset and rule times that equal their defaults.
+func (tz TZString) String() string {
+	var sb strings.Builder
+	sb.WriteString(formatTZName(tz.Std))
+	sb.WriteString(formatTZOffset(-tz.StdOff))
+	if tz.DST == "" {
+		return sb.String()
+	}
+	sb.WriteString(formatTZName(tz.DST))
+	if tz.DSTOff != tz.StdOff+3600 {
+		sb.WriteString(formatTZOffset(-tz.DSTOff))
+	}
+	if tz.Start != nil && tz.End != nil {
+		sb.WriteString("," + tz.Start.String() + "," + tz.End.String())
+	}
+	return sb.String()
+}
+
+// String formats r as in a POSIX TZ string, omitting the time if it is the default 02:00.
+func (r Rule) String() string {
+	var s string
+	switch r.Kind {
+	case RuleJulian:
+		s = fmt.Sprintf("J%d", r.Day)
+	case RuleZeroJulian:
+		s = fmt.Sprintf("%d", r.Day)
+	default:
+		s = fmt.Sprintf("M%d.%d.%d", r.Month, r.Week, r.Weekday)
+	}
+	if r.Time != 7200 {
+		s += "/" + formatTZOffset(r.Time)
+	}
+	return s
+}
+
+// usesVersion3 reports whether the rule times are outside the 0..24 hours permitted before version 3.
+func (tz *TZString) usesVersion3() bool {
+	for _, r := range []*Rule{tz.Start, tz.End} {
+		if r != nil && (r.Time < 0 || r.Time > 24*3600) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatTZName quotes name in angle brackets unless it is alphabetic.
+func formatTZName(name string) string {
+	for i := 0; i < len(name); i++ {
+		if !isAlpha(name[i]) {
+			return "<" + name + ">"
+		}
+	}
+	return name
+}
+
+// formatTZOffset formats seconds as [-]hh[:mm[:ss]].
+func formatTZOffset(secs int32) string {
+	sign := ""
+	if secs < 0 {
+		sign = "-"
+		secs = -secs
+	}
+	s := fmt.Sprintf("%s%d", sign, secs/3600)
+	if secs%3600 != 0 {
+		s += fmt.Sprintf(":%02d", secs/60%60)
+		if secs%60 != 0 {
+			s += fmt.Sprintf(":%02d", secs%60)
+		}
+	}
+	return s
+}
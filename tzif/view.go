@@ -0,0 +1,100 @@
+package tzif
+
+import (
+	"fmt"
+	"time"
+)
+
+// View is a parsed TZif file with the indexes of its most precise data block resolved, so that each
+// transition refers to its local time type and each type to its designation.
+// It is the context of the templates executed by tzif2text -template, which makes its fields and
+// methods, and those of the types it refers to, part of the API.
+type View struct {
+	// Name is the name of the input the file was read from, empty if unknown.
+	Name string
+	// File is the parsed file, for the data not covered by the other fields.
+	File *File
+	// Version is the version of the file, 1 to 4.
+	Version uint8
+	// Types are the local time types of the data block in the order of their records.
+	Types []Type
+	// Transitions are the transitions of the data block in the order of the transition table.
+	Transitions []Transition
+	// LeapSeconds are the leap second records of the data block.
+	LeapSeconds []LeapSecond
+	// Footer is the parsed TZ string of the footer. It is nil for version 1 files and empty TZ strings.
+	Footer *TZString
+}
+
+// Type is a local time type with its designation and indicators.
+type Type struct {
+	LocalTimeType
+	// Index is the index of the local time type record.
+	Index int
+	// Designation is the time zone designation Idx refers to.
+	Designation string
+	// IsStd and IsUT are the standard/wall and UT/local indicators, false if the file has none.
+	IsStd, IsUT bool
+}
+
+// Transition is a transition together with the local time type it selects.
+type Transition struct {
+	// Index is the index of the transition in the transition table.
+	Index int
+	// Unix is the transition time in seconds since the Unix epoch.
+	Unix int64
+	// Type is the local time type in effect from the transition on.
+	Type *Type
+}
+
+// Time returns the transition time in UTC.
+func (t Transition) Time() time.Time {
+	return time.Unix(t.Unix, 0).UTC()
+}
+
+// NewView resolves f read from the input called name. It fails if an index of the data block is out
+// of range or the footer cannot be parsed.
+func NewView(f *File, name string) (*View, error) {
+	b := &f.V1
+	if f.V2 != nil {
+		b = f.V2
+	}
+	v := &View{
+		Name:        name,
+		File:        f,
+		Version:     b.Header.Version,
+		LeapSeconds: b.LeapSeconds,
+	}
+	for i, lt := range b.LocalTimeTypes {
+		desig, err := b.Designation(lt.Idx)
+		if err != nil {
+			return nil, fmt.Errorf("local time type %d: %v", i, err)
+		}
+		t := Type{LocalTimeType: lt, Index: i, Designation: desig}
+		if i < len(b.IsStd) {
+			t.IsStd = b.IsStd[i]
+		}
+		if i < len(b.IsUT) {
+			t.IsUT = b.IsUT[i]
+		}
+		v.Types = append(v.Types, t)
+	}
+	for i, ts := range b.TransitionTimes {
+		if i >= len(b.TransitionTypes) {
+			return nil, fmt.Errorf("missing transition type %d", i)
+		}
+		typ := int(b.TransitionTypes[i])
+		if typ >= len(v.Types) {
+			return nil, fmt.Errorf("transition %d: local time type %d out of range", i, typ)
+		}
+		v.Transitions = append(v.Transitions, Transition{Index: i, Unix: ts, Type: &v.Types[typ]})
+	}
+	if f.V2 != nil {
+		tz, err := ParseFooter(f.Footer)
+		if err != nil {
+			return nil, fmt.Errorf("footer: %v", err)
+		}
+		v.Footer = tz
+	}
+	return v, nil
+}
@@ -0,0 +1,103 @@
+package tzif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// WriteTo encodes f as TZif and writes it to w. The counts in the headers are derived from the lengths
+// of the data block slices, so an edited File is written consistently; the counts stored in Header
+// are ignored and only its Version is used.
+func (f *File) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	err := writeDataBlock(&buf, &f.V1, 4)
+	if err != nil {
+		return 0, fmt.Errorf("v1 data block: %v", err)
+	}
+	if f.V2 != nil {
+		err = writeDataBlock(&buf, f.V2, 8)
+		if err != nil {
+			return 0, fmt.Errorf("v2+ data block: %v", err)
+		}
+		buf.Write(f.Footer)
+	}
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
+
+// Encode returns f encoded as TZif, see File.WriteTo.
+func Encode(f *File) ([]byte, error) {
+	var buf bytes.Buffer
+	_, err := f.WriteTo(&buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeDataBlock writes the header and data block b with timeSize-byte times to buf.
+func writeDataBlock(buf *bytes.Buffer, b *DataBlock, timeSize int) error {
+	if len(b.TransitionTypes) != len(b.TransitionTimes) {
+		return fmt.Errorf("%d transition types for %d transition times", len(b.TransitionTypes), len(b.TransitionTimes))
+	}
+	if len(b.LocalTimeTypes) > 256 {
+		return fmt.Errorf("%d local time types, at most 256 are allowed", len(b.LocalTimeTypes))
+	}
+	buf.WriteString(Magic)
+	switch b.Header.Version {
+	case 1:
+		buf.WriteByte(0)
+	case 2, 3, 4:
+		buf.WriteByte('0' + b.Header.Version)
+	default:
+		return fmt.Errorf("unsupported version: %d", b.Header.Version)
+	}
+	buf.Write(make([]byte, 15))
+	for _, cnt := range []int{len(b.IsUT), len(b.IsStd), len(b.LeapSeconds), len(b.TransitionTimes), len(b.LocalTimeTypes), len(b.Designations)} {
+		binary.Write(buf, binary.BigEndian, uint32(cnt))
+	}
+	writeTime := func(t int64) error {
+		if timeSize == 8 {
+			return binary.Write(buf, binary.BigEndian, t)
+		}
+		if t < math.MinInt32 || t > math.MaxInt32 {
+			return fmt.Errorf("time %d does not fit in 32 bits", t)
+		}
+		return binary.Write(buf, binary.BigEndian, int32(t))
+	}
+	for _, ts := range b.TransitionTimes {
+		if err := writeTime(ts); err != nil {
+			return err
+		}
+	}
+	buf.Write(b.TransitionTypes)
+	for _, t := range b.LocalTimeTypes {
+		binary.Write(buf, binary.BigEndian, t.UTOff)
+		buf.WriteByte(t.DST)
+		buf.WriteByte(t.Idx)
+	}
+	buf.Write(b.Designations)
+	for _, ls := range b.LeapSeconds {
+		if err := writeTime(ls.Occur); err != nil {
+			return err
+		}
+		binary.Write(buf, binary.BigEndian, ls.Corr)
+	}
+	for _, isStd := range b.IsStd {
+		buf.WriteByte(boolByte(isStd))
+	}
+	for _, isUT := range b.IsUT {
+		buf.WriteByte(boolByte(isUT))
+	}
+	return nil
+}
+
+func boolByte(v bool) byte {
+	if v {
+		return 1
+	}
+	return 0
+}
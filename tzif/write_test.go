@@ -0,0 +1,83 @@
+package tzif
+
+import (
+	"bytes"
+	"embed"
+	"testing"
+)
+
+// testdata holds zoneinfo files written by zic: fat ones with the default -b fat, slim ones with -b slim,
+// and right-utc.tzif with leap seconds.
+//
+//go:embed testdata/*.tzif
+var testdata embed.FS
+
+// readTestdata returns the contents of testdata/name.
+func readTestdata(t testing.TB, name string) []byte {
+	t.Helper()
+	data, err := testdata.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestWriteToRoundTrip(t *testing.T) {
+	v2 := testZone()
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"built v1", testFile{version: 1, v1: testZone()}.bytes()},
+		{"built fat v4", testFile{version: 4, v1: testZone(), v2: &v2, footer: "\nCET-1CEST,M3.5.0,M10.5.0/3\n"}.bytes()},
+		{"built slim", testSlim(testZone()).bytes()},
+	}
+	for _, name := range []string{"prague-fat.tzif", "prague-slim.tzif", "right-utc.tzif", "sydney-slim.tzif",
+		"tokyo-slim.tzif", "utc.tzif"} {
+		tests = append(tests, struct {
+			name string
+			data []byte
+		}{name, readTestdata(t, name)})
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := Parse(bytes.NewReader(tc.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			n, err := f.WriteTo(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != int64(buf.Len()) {
+				t.Errorf("WriteTo returned %d, wrote %d bytes", n, buf.Len())
+			}
+			if !bytes.Equal(buf.Bytes(), tc.data) {
+				t.Errorf("round trip differs:\n got %x\nwant %x", buf.Bytes(), tc.data)
+			}
+		})
+	}
+}
+
+func TestWriteToDerivesCounts(t *testing.T) {
+	f, err := Parse(bytes.NewReader(readTestdata(t, "prague-slim.tzif")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Stale counts in the header are ignored.
+	f.V2.TransitionTimes = f.V2.TransitionTimes[:10]
+	f.V2.TransitionTypes = f.V2.TransitionTypes[:10]
+	f.V2.Header.TimeCnt = 1000
+	data, err := Encode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := Options{Strict: true}.Parse(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.V2.Header.TimeCnt != 10 || !bytes.Equal(g.Footer, f.Footer) {
+		t.Errorf("timecnt %d, footer %q, want 10 and %q", g.V2.Header.TimeCnt, g.Footer, f.Footer)
+	}
+}
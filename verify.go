@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/martin-sucha/tzif2text/tzif"
+)
+
+// verifyStdlib loads data with the time package and compares its interpretation with f one second before,
+// at and one second after every change of local time before zdumpHiYear. Disagreements are printed one per
+// line prefixed with name, and an error is returned if there are any.
+func verifyStdlib(data []byte, f *tzif.File, name string) error {
+	loc, err := time.LoadLocationFromTZData(name, data)
+	if err != nil {
+		return fmt.Errorf("time package: %v", err)
+	}
+	hi := time.Date(zdumpHiYear, time.January, 1, 0, 0, 0, 0, time.UTC).Unix()
+	disagreements := 0
+	for _, change := range changeCandidates(f, hi) {
+		for _, t := range []int64{change - 1, change, change + 1} {
+			z, err := f.Lookup(t)
+			if err != nil {
+				fmt.Fprintf(stdout, "%s: at %s: %v\n", name, diffTime(t), err)
+				disagreements++
+				continue
+			}
+			lt := time.Unix(t, 0).In(loc)
+			stdName, stdOffset := lt.Zone()
+			if z.Name != stdName || int(z.UTOff) != stdOffset || z.DST != lt.IsDST() {
+				fmt.Fprintf(stdout, "%s: at %s: tzif2text %s utoff=%d dst=%t, time package %s utoff=%d dst=%t\n",
+					name, diffTime(t), z.Name, z.UTOff, z.DST, stdName, stdOffset, lt.IsDST())
+				disagreements++
+			}
+		}
+	}
+	if disagreements > 0 {
+		return fmt.Errorf("%d disagreement(s) with the time package", disagreements)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/martin-sucha/tzif2text/tzif"
+)
+
+// vtimezoneRDateYear is the last year for which the transitions of footer rules that cannot be expressed
+// as RRULE are listed as RDATE, the end of the 32-bit time range zic -b fat also covers.
+const vtimezoneRDateYear = 2037
+
+// vtimezoneComponent is a STANDARD or DAYLIGHT sub-component of a VTIMEZONE.
+type vtimezoneComponent struct {
+	dst      bool
+	from, to int32
+	name     string
+	// start is the first onset in local time given by from, rdates are the following ones.
+	start  time.Time
+	rdates []time.Time
+	rrule  string
+}
+
+// printVtimezone prints f as an RFC 5545 VTIMEZONE component with TZID name. The transitions the footer
+// TZ string generates are omitted from the transition table and expressed as RRULE instead.
+func printVtimezone(f *tzif.File, name string) error {
+	b := lastBlock(f)
+	var tz *tzif.TZString
+	if f.V2 != nil {
+		var err error
+		tz, err = parseFooter(f)
+		if err != nil {
+			return err
+		}
+	}
+	keep := len(b.TransitionTimes)
+	if tz != nil {
+		var err error
+		keep, err = b.RedundantFrom(tz)
+		if err != nil {
+			return err
+		}
+	}
+
+	var components []*vtimezoneComponent
+	// Onsets with the same offsets and designation share a component, the following ones are listed as RDATE.
+	byKey := make(map[string]*vtimezoneComponent)
+	add := func(t int64, from, to tzif.Zone) {
+		key := fmt.Sprintf("%t %d %d %s", to.DST, from.UTOff, to.UTOff, to.Name)
+		local := time.Unix(t+int64(from.UTOff), 0).UTC()
+		if c, ok := byKey[key]; ok {
+			c.rdates = append(c.rdates, local)
+			return
+		}
+		c := &vtimezoneComponent{dst: to.DST, from: from.UTOff, to: to.UTOff, name: to.Name, start: local}
+		byKey[key] = c
+		components = append(components, c)
+	}
+	for _, t := range b.TransitionTimes[:keep] {
+		from, err := b.Lookup(t - 1)
+		if err != nil {
+			return err
+		}
+		to, err := b.Lookup(t)
+		if err != nil {
+			return err
+		}
+		add(t, from, to)
+	}
+	rules := false
+	if tz != nil {
+		_, _, rules = tz.Transitions(0)
+	}
+	if keep == 0 && !rules {
+		// A single local time type applies at all times, starting at the epoch is arbitrary.
+		z, err := f.Lookup(0)
+		if err != nil {
+			return err
+		}
+		components = append(components, &vtimezoneComponent{dst: z.DST, from: z.UTOff, to: z.UTOff, name: z.Name,
+			start: time.Unix(int64(z.UTOff), 0).UTC()})
+	}
+	if rules {
+		// Without transitions, the rules apply from the epoch.
+		after := int64(0)
+		if keep > 0 {
+			after = b.TransitionTimes[keep-1]
+		}
+		components = append(components, footerComponent(tz, after, false), footerComponent(tz, after, true))
+	}
+
+	fmt.Fprint(stdout, "BEGIN:VTIMEZONE\r\n")
+	fmt.Fprintf(stdout, "TZID:%s\r\n", vtimezoneText(strings.TrimPrefix(name, zoneinfoDir("")+"/")))
+	for _, c := range components {
+		kind := "STANDARD"
+		if c.dst {
+			kind = "DAYLIGHT"
+		}
+		fmt.Fprintf(stdout, "BEGIN:%s\r\n", kind)
+		fmt.Fprintf(stdout, "DTSTART:%s\r\n", c.start.Format("20060102T150405"))
+		fmt.Fprintf(stdout, "TZOFFSETFROM:%s\r\n", vtimezoneOffset(c.from))
+		fmt.Fprintf(stdout, "TZOFFSETTO:%s\r\n", vtimezoneOffset(c.to))
+		if c.name != "" {
+			fmt.Fprintf(stdout, "TZNAME:%s\r\n", vtimezoneText(capDesignation(c.name)))
+		}
+		if c.rrule != "" {
+			fmt.Fprintf(stdout, "RRULE:%s\r\n", c.rrule)
+		}
+		for _, t := range c.rdates {
+			fmt.Fprintf(stdout, "RDATE:%s\r\n", t.Format("20060102T150405"))
+		}
+		fmt.Fprintf(stdout, "END:%s\r\n", kind)
+	}
+	fmt.Fprint(stdout, "END:VTIMEZONE\r\n")
+	return nil
+}
+
+// footerComponent returns the component of the transitions to DST, or from DST if end is set,
+// that the rules of tz generate after the instant after.
+func footerComponent(tz *tzif.TZString, after int64, end bool) *vtimezoneComponent {
+	c := &vtimezoneComponent{dst: true, from: tz.StdOff, to: tz.DSTOff, name: tz.DST}
+	rule := tz.Start
+	if end {
+		c = &vtimezoneComponent{from: tz.DSTOff, to: tz.StdOff, name: tz.Std}
+		rule = tz.End
+	}
+	year := time.Unix(after+int64(tz.StdOff), 0).UTC().Year()
+	next := func() time.Time {
+		for ; ; year++ {
+			t, tEnd, _ := tz.Transitions(year)
+			if end {
+				t = tEnd
+			}
+			if t > after {
+				year++
+				return time.Unix(t+int64(c.from), 0).UTC()
+			}
+		}
+	}
+	c.start = next()
+	c.rrule = vtimezoneRRule(*rule)
+	if c.rrule == "" {
+		for year <= vtimezoneRDateYear {
+			c.rdates = append(c.rdates, next())
+		}
+	}
+	return c
+}
+
+// vtimezoneRRule returns the RRULE generating the transitions of r, or an empty string if r's time
+// is outside the day, which RRULE cannot express.
+func vtimezoneRRule(r tzif.Rule) string {
+	if r.Time < 0 || r.Time >= 24*60*60 {
+		return ""
+	}
+	switch r.Kind {
+	case tzif.RuleJulian:
+		// February 29 is never counted, so the day falls on the same date every year.
+		day := time.Date(2001, time.January, r.Day, 0, 0, 0, 0, time.UTC)
+		return fmt.Sprintf("FREQ=YEARLY;BYMONTH=%d;BYMONTHDAY=%d", day.Month(), day.Day())
+	case tzif.RuleZeroJulian:
+		return fmt.Sprintf("FREQ=YEARLY;BYYEARDAY=%d", r.Day+1)
+	default:
+		week := r.Week
+		if week == 5 {
+			week = -1
+		}
+		days := []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+		return fmt.Sprintf("FREQ=YEARLY;BYMONTH=%d;BYDAY=%d%s", r.Month, week, days[r.Weekday])
+	}
+}
+
+// vtimezoneOffset formats a UT offset as the UTC-OFFSET value type of RFC 5545, ±hhmm[ss].
+func vtimezoneOffset(off int32) string {
+	sign := '+'
+	if off < 0 {
+		sign = '-'
+		off = -off
+	}
+	s := fmt.Sprintf("%c%02d%02d", sign, off/3600, off/60%60)
+	if off%60 != 0 {
+		s += fmt.Sprintf("%02d", off%60)
+	}
+	return s
+}
+
+// vtimezoneText escapes s as the TEXT value type of RFC 5545.
+func vtimezoneText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/martin-sucha/tzif2text/tzif"
+)
+
+// zdumpHiYear is the year before which zdump reports transitions by default.
+const zdumpHiYear = 2500
+
+// printZdump prints the changes of local time like zdump -v, or zdump -V if verbose is false,
+// name is printed as the zone name.
+func printZdump(f *tzif.File, name string, verbose bool) error {
+	hi := time.Date(zdumpHiYear, time.January, 1, 0, 0, 0, 0, time.UTC).Unix()
+	if verbose {
+		// zdump -v also probes the extremes of the time range, where local time cannot be represented.
+		fmt.Fprintf(stdout, "%s  %d = NULL\n", name, int64(math.MinInt64))
+		fmt.Fprintf(stdout, "%s  %d = NULL\n", name, int64(math.MinInt64)+86400)
+	}
+	b := lastBlock(f)
+	// zdump reports the second after each leap second as a change of local time.
+	afterLeap := make(map[int64]bool)
+	for _, ls := range b.LeapSeconds {
+		afterLeap[ls.Occur+1] = true
+	}
+	times := changeCandidates(f, hi)
+	for t := range afterLeap {
+		times = append(times, t)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	for i, t := range times {
+		if i > 0 && times[i-1] == t {
+			continue
+		}
+		before, err := f.Lookup(t - 1)
+		if err != nil {
+			return err
+		}
+		after, err := f.Lookup(t)
+		if err != nil {
+			return err
+		}
+		if before.Name == after.Name && before.UTOff == after.UTOff && before.DST == after.DST && !afterLeap[t] {
+			continue
+		}
+		printZdumpLine(name, b, t-1, before)
+		printZdumpLine(name, b, t, after)
+	}
+	if verbose {
+		fmt.Fprintf(stdout, "%s  %d = NULL\n", name, int64(math.MaxInt64)-86400)
+		fmt.Fprintf(stdout, "%s  %d = NULL\n", name, int64(math.MaxInt64))
+	}
+	return nil
+}
+
+// changeCandidates returns the sorted instants before hi at which local time may change:
+// the transitions of the most precise data block and those of the footer rules after it.
+func changeCandidates(f *tzif.File, hi int64) []int64 {
+	b := lastBlock(f)
+	var times []int64
+	for _, t := range b.TransitionTimes {
+		if t < hi {
+			times = append(times, t)
+		}
+	}
+	if f.V2 == nil {
+		return times
+	}
+	tz, err := tzif.ParseFooter(f.Footer)
+	if err != nil || tz == nil {
+		return times
+	}
+	var last int64 = math.MinInt64
+	if len(times) > 0 {
+		last = times[len(times)-1]
+	}
+	year := 1970
+	if last != math.MinInt64 {
+		year = time.Unix(last, 0).UTC().Year()
+	}
+	var rule []int64
+	for ; year < zdumpHiYear; year++ {
+		start, end, ok := tz.Transitions(year)
+		if !ok {
+			break
+		}
+		for _, t := range []int64{start, end} {
+			if t > last && t < hi {
+				rule = append(rule, t)
+			}
+		}
+	}
+	sort.Slice(rule, func(i, j int) bool { return rule[i] < rule[j] })
+	return append(times, rule...)
+}
+
+// printZdumpLine prints the UT and local time at t, correcting for the leap seconds of b.
+func printZdumpLine(name string, b *tzif.DataBlock, t int64, z tzif.Zone) {
+	corr, hit := leapCorrection(b, t)
+	dst := 0
+	if z.DST {
+		dst = 1
+	}
+	fmt.Fprintf(stdout, "%s  %s UT = %s %s isdst=%d gmtoff=%d\n", name, formatAsctime(t-corr, hit),
+		formatAsctime(t-corr+int64(z.UTOff), hit), capDesignation(z.Name), dst, z.UTOff)
+}
+
+// leapCorrection returns the leap second correction in effect at t and whether t is an inserted leap second.
+func leapCorrection(b *tzif.DataBlock, t int64) (int64, bool) {
+	for i := len(b.LeapSeconds) - 1; i >= 0; i-- {
+		ls := b.LeapSeconds[i]
+		if t < ls.Occur {
+			continue
+		}
+		var prev int32
+		if i > 0 {
+			prev = b.LeapSeconds[i-1].Corr
+		}
+		return int64(ls.Corr), t == ls.Occur && ls.Corr > prev
+	}
+	return 0, false
+}
+
+// formatAsctime formats t like asctime, with 60 seconds if leap is set.
+func formatAsctime(t int64, leap bool) string {
+	if leap {
+		return time.Unix(t, 0).UTC().Format("Mon Jan _2 15:04:") + "60" + time.Unix(t, 0).UTC().Format(" 2006")
+	}
+	return time.Unix(t, 0).UTC().Format("Mon Jan _2 15:04:05 2006")
+}
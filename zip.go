@@ -0,0 +1,82 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/martin-sucha/tzif2text/tzif"
+)
+
+// openInput opens the input file called name. With -zip, it opens the member of the archive instead.
+var openInput = func(name string) (io.ReadCloser, error) {
+	return os.Open(name)
+}
+
+// eocdSignature starts the end of central directory record of a zip archive.
+const eocdSignature = "PK\x05\x06"
+
+// eocdSize is the length of the end of central directory record without the comment.
+const eocdSize = 22
+
+// openZip opens the zip archive at path, such as $GOROOT/lib/time/zoneinfo.zip. The archive may also be
+// embedded in a larger file, as in Go executables built with the time/tzdata package.
+func openZip(path string) (*zip.Reader, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if z, err := zip.NewReader(bytes.NewReader(data), int64(len(data))); err == nil {
+		return z, nil
+	}
+	// Look for the end of an embedded archive, the latest first. archive/zip locates its start from there.
+	for end := len(data); ; {
+		i := bytes.LastIndex(data[:end], []byte(eocdSignature))
+		if i < 0 {
+			return nil, fmt.Errorf("%s: no zip archive found", path)
+		}
+		end = i
+		if i+eocdSize > len(data) {
+			continue
+		}
+		n := i + eocdSize + int(binary.LittleEndian.Uint16(data[i+eocdSize-2:]))
+		if n > len(data) {
+			continue
+		}
+		z, err := zip.NewReader(bytes.NewReader(data[:n]), int64(n))
+		if err == nil && len(z.File) > 0 {
+			return z, nil
+		}
+	}
+}
+
+// listZip prints the names of the members of z, or their summaries with -summary.
+func listZip(z *zip.Reader) error {
+	for _, member := range z.File {
+		if member.FileInfo().IsDir() {
+			continue
+		}
+		if !*summary {
+			fmt.Fprintln(stdout, member.Name)
+			continue
+		}
+		rc, err := member.Open()
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return &inputError{name: member.Name, err: err}
+		}
+		f, err := tzif.Parse(bytes.NewReader(data))
+		if err != nil {
+			return &inputError{name: member.Name, err: err}
+		}
+		fmt.Fprintln(stdout, summaryLine(member.Name, f, len(data)))
+	}
+	return nil
+}